@@ -0,0 +1,213 @@
+// Package auth verifies the credentials of requests, such as JWT bearer
+// tokens, API keys and basic auth, from HTTP headers or gRPC metadata.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrNoCredentials means the request carries no credentials the
+	// authenticator understands.
+	ErrNoCredentials = errors.New("auth: no credentials")
+	// ErrInvalidCredentials means the credentials were rejected.
+	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	Subject string
+	// Method names how the caller authenticated: "jwt", "apikey" or
+	// "basic".
+	Method string
+	Roles  []string
+	Scopes []string
+	// Claims holds the JWT claims, or extra attributes of other methods.
+	Claims map[string]any
+}
+
+func (p *Principal) clone() *Principal {
+	cp := *p
+	cp.Roles = slices.Clone(p.Roles)
+	cp.Scopes = slices.Clone(p.Scopes)
+	cp.Claims = maps.Clone(p.Claims)
+	return &cp
+}
+
+// HasAnyRole reports whether p has at least one of roles.
+func (p *Principal) HasAnyRole(roles ...string) bool {
+	for _, r := range roles {
+		for _, have := range p.Roles {
+			if r == have {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasScopes reports whether p has every one of scopes.
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, s := range scopes {
+		found := false
+		for _, have := range p.Scopes {
+			if s == have {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type principalKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal of an authenticated request,
+// or nil.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Header reads request headers, such as http.Header or gRPC metadata.
+type Header interface {
+	Get(key string) string
+}
+
+// Authenticator finds credentials in request headers and verifies them.
+// It returns ErrNoCredentials when there are none it understands.
+type Authenticator interface {
+	Authenticate(ctx context.Context, h Header) (*Principal, error)
+}
+
+type AuthenticatorFunc func(ctx context.Context, h Header) (*Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, h Header) (*Principal, error) {
+	return f(ctx, h)
+}
+
+// TokenVerifier verifies an opaque credential, such as a JWT or API key.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+type TokenVerifierFunc func(ctx context.Context, token string) (*Principal, error)
+
+func (f TokenVerifierFunc) Verify(ctx context.Context, token string) (*Principal, error) {
+	return f(ctx, token)
+}
+
+// Bearer authenticates "Authorization: Bearer <token>" with v.
+func Bearer(v TokenVerifier) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, h Header) (*Principal, error) {
+		token, ok := cutScheme(h.Get("Authorization"), "Bearer")
+		if !ok || token == "" {
+			return nil, ErrNoCredentials
+		}
+		return v.Verify(ctx, token)
+	})
+}
+
+// APIKey authenticates the key sent in header, such as X-API-Key, with v.
+func APIKey(header string, v TokenVerifier) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, h Header) (*Principal, error) {
+		key := h.Get(header)
+		if key == "" {
+			return nil, ErrNoCredentials
+		}
+		return v.Verify(ctx, key)
+	})
+}
+
+// BasicVerifier verifies a username and password.
+type BasicVerifier func(ctx context.Context, username, password string) (*Principal, error)
+
+// Basic authenticates "Authorization: Basic <credentials>" with v.
+func Basic(v BasicVerifier) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, h Header) (*Principal, error) {
+		encoded, ok := cutScheme(h.Get("Authorization"), "Basic")
+		if !ok {
+			return nil, ErrNoCredentials
+		}
+		b, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, ErrInvalidCredentials
+		}
+		username, password, ok := strings.Cut(string(b), ":")
+		if !ok {
+			return nil, ErrInvalidCredentials
+		}
+		return v(ctx, username, password)
+	})
+}
+
+// Any tries each authenticator in turn and returns the result of the
+// first that finds credentials.
+func Any(auths ...Authenticator) Authenticator {
+	return AuthenticatorFunc(func(ctx context.Context, h Header) (*Principal, error) {
+		for _, a := range auths {
+			p, err := a.Authenticate(ctx, h)
+			if !errors.Is(err, ErrNoCredentials) {
+				return p, err
+			}
+		}
+		return nil, ErrNoCredentials
+	})
+}
+
+func cutScheme(authorization, scheme string) (string, bool) {
+	if len(authorization) <= len(scheme) || !strings.EqualFold(authorization[:len(scheme)], scheme) || authorization[len(scheme)] != ' ' {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(scheme)+1:]), true
+}
+
+// StaticAPIKeys verifies keys against a fixed set, mapping each key to its
+// principal. Keys are compared by hash to avoid leaking them through
+// timing.
+func StaticAPIKeys(keys map[string]*Principal) TokenVerifier {
+	hashed := make(map[[sha256.Size]byte]*Principal, len(keys))
+	for k, p := range keys {
+		p = p.clone()
+		if p.Method == "" {
+			p.Method = "apikey"
+		}
+		hashed[sha256.Sum256([]byte(k))] = p
+	}
+	return TokenVerifierFunc(func(_ context.Context, key string) (*Principal, error) {
+		p, ok := hashed[sha256.Sum256([]byte(key))]
+		if !ok {
+			return nil, ErrInvalidCredentials
+		}
+		// Callers may modify the principal of their request.
+		return p.clone(), nil
+	})
+}
+
+// StaticUsers verifies basic auth against fixed passwords by username.
+// The principal's roles come from roles, by username.
+func StaticUsers(passwords map[string]string, roles map[string][]string) BasicVerifier {
+	return func(_ context.Context, username, password string) (*Principal, error) {
+		want, ok := passwords[username]
+		// Compare even for unknown users, so timing does not reveal them.
+		match := subtle.ConstantTimeCompare([]byte(password), []byte(want)) == 1
+		if !ok || !match {
+			return nil, ErrInvalidCredentials
+		}
+		return &Principal{Subject: username, Method: "basic", Roles: slices.Clone(roles[username])}, nil
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAny(t *testing.T) {
+	billing := &Principal{Subject: "billing", Scopes: []string{"invoices:read"}}
+	a := Any(
+		APIKey("X-API-Key", StaticAPIKeys(map[string]*Principal{"k-123": billing})),
+		Basic(StaticUsers(map[string]string{"alice": "s3cret"}, map[string][]string{"alice": {"admin"}})),
+	)
+	ctx := context.Background()
+
+	p, err := a.Authenticate(ctx, http.Header{"X-Api-Key": {"k-123"}})
+	require.NoError(t, err)
+	assert.Equal(t, "billing", p.Subject)
+	assert.Equal(t, "apikey", p.Method)
+	assert.True(t, p.HasScopes("invoices:read"))
+	assert.Empty(t, billing.Method)
+
+	// Principals are not shared between requests.
+	p.Scopes[0] = "invoices:write"
+	p, err = a.Authenticate(ctx, http.Header{"X-Api-Key": {"k-123"}})
+	require.NoError(t, err)
+	assert.True(t, p.HasScopes("invoices:read"))
+
+	_, err = a.Authenticate(ctx, http.Header{"X-Api-Key": {"wrong"}})
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("alice", "s3cret")
+	p, err = a.Authenticate(ctx, req.Header)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.Subject)
+	assert.True(t, p.HasAnyRole("viewer", "admin"))
+	assert.False(t, p.HasScopes("invoices:read"))
+
+	req.SetBasicAuth("alice", "guess")
+	_, err = a.Authenticate(ctx, req.Header)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = a.Authenticate(ctx, http.Header{"Authorization": {"Bearer abc"}})
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
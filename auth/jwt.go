@@ -0,0 +1,513 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyper-micro/hyper/internal/json"
+)
+
+// Key is a JWT signing key: a []byte secret for HS*, or an *rsa.PublicKey,
+// *ecdsa.PublicKey or ed25519.PublicKey. To sign, private keys are used in
+// their place.
+type Key struct {
+	// ID matches the kid header of tokens.
+	ID string
+	// Algorithm, when set, is the only alg the key verifies.
+	Algorithm string
+	Key       any
+}
+
+type JWTOption struct {
+	// Keys verify signatures. A token with a kid header is only checked
+	// with keys of that ID, while one without is checked with every key.
+	Keys []Key
+	// JWKSFile loads more keys from a JSON Web Key Set file when the
+	// verifier is created.
+	JWKSFile string
+	// JWKSURL loads more keys from a JSON Web Key Set endpoint on first
+	// use, then every RefreshInterval and on unknown key IDs, at most once
+	// a minute.
+	JWKSURL string
+	// RefreshInterval defaults to an hour.
+	RefreshInterval time.Duration
+	// HTTPClient fetches JWKSURL, defaulting to a client with a 10s
+	// timeout.
+	HTTPClient *http.Client
+	// Issuer, when set, must equal the iss claim.
+	Issuer string
+	// Audience, when set, must be among the aud claim.
+	Audience string
+	// Algorithms restricts the accepted alg headers. By default any alg
+	// suiting the key is accepted.
+	Algorithms []string
+	// Leeway tolerates clock skew in exp and nbf.
+	Leeway time.Duration
+	// RolesClaim names the claim holding roles, defaulting to "roles".
+	RolesClaim string
+}
+
+const jwksRefetchInterval = time.Minute
+
+// JWTVerifier verifies JWTs and turns their claims into principals.
+type JWTVerifier struct {
+	opt JWTOption
+	now func() time.Time
+
+	fetchMu   sync.Mutex
+	mu        sync.RWMutex
+	remote    []Key
+	fetchedAt time.Time
+	fetchErr  error
+}
+
+func NewJWTVerifier(opt JWTOption) (*JWTVerifier, error) {
+	if opt.RefreshInterval <= 0 {
+		opt.RefreshInterval = time.Hour
+	}
+	if opt.HTTPClient == nil {
+		opt.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opt.RolesClaim == "" {
+		opt.RolesClaim = "roles"
+	}
+	opt.Keys = append([]Key(nil), opt.Keys...)
+	if opt.JWKSFile != "" {
+		b, err := os.ReadFile(opt.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth: read JWKS: %w", err)
+		}
+		keys, err := ParseJWKS(b)
+		if err != nil {
+			return nil, err
+		}
+		opt.Keys = append(opt.Keys, keys...)
+	}
+	if len(opt.Keys) == 0 && opt.JWKSURL == "" {
+		return nil, errors.New("auth: JWT verifier has no keys")
+	}
+	return &JWTVerifier{opt: opt, now: time.Now}, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidCredentials}, args...)...)
+}
+
+// Verify checks the signature and registered claims of token. Rejected
+// tokens return an error wrapping ErrInvalidCredentials.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("malformed token")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, invalid("malformed header")
+	}
+	if !v.algorithmAllowed(header.Alg) {
+		return nil, invalid("algorithm %q not allowed", header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("malformed signature")
+	}
+
+	keys, err := v.keys(ctx, header.Kid)
+	if len(keys) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		return nil, invalid("unknown key %q", header.Kid)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, k := range keys {
+		if k.Algorithm != "" && k.Algorithm != header.Alg {
+			continue
+		}
+		if verifySignature(header.Alg, k.Key, signed, sig) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, invalid("signature mismatch")
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, invalid("malformed claims")
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return v.principal(claims), nil
+}
+
+func (v *JWTVerifier) algorithmAllowed(alg string) bool {
+	if alg == "" || strings.EqualFold(alg, "none") {
+		return false
+	}
+	if len(v.opt.Algorithms) == 0 {
+		return true
+	}
+	for _, a := range v.opt.Algorithms {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *JWTVerifier) validateClaims(claims map[string]any) error {
+	now := v.now()
+	if exp, ok := numericDate(claims["exp"]); ok && !now.Before(exp.Add(v.opt.Leeway)) {
+		return invalid("token expired")
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(v.opt.Leeway).Before(nbf) {
+		return invalid("token not valid yet")
+	}
+	if v.opt.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.opt.Issuer {
+			return invalid("unexpected issuer %q", iss)
+		}
+	}
+	if v.opt.Audience != "" {
+		found := false
+		for _, aud := range stringList(claims["aud"], false) {
+			if aud == v.opt.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return invalid("audience %q not accepted", v.opt.Audience)
+		}
+	}
+	return nil
+}
+
+func (v *JWTVerifier) principal(claims map[string]any) *Principal {
+	p := &Principal{Method: "jwt", Claims: claims}
+	p.Subject, _ = claims["sub"].(string)
+	p.Roles = stringList(claims[v.opt.RolesClaim], true)
+	if scope, ok := claims["scope"]; ok {
+		p.Scopes = stringList(scope, true)
+	} else {
+		p.Scopes = stringList(claims["scp"], true)
+	}
+	return p
+}
+
+// keys returns the keys a token with kid may be signed with, fetching
+// JWKSURL when due.
+func (v *JWTVerifier) keys(ctx context.Context, kid string) ([]Key, error) {
+	if v.opt.JWKSURL == "" {
+		return matchKeys(nil, v.opt.Keys, kid), nil
+	}
+	err := v.refresh(ctx, v.opt.RefreshInterval)
+	v.mu.RLock()
+	keys := matchKeys(matchKeys(nil, v.opt.Keys, kid), v.remote, kid)
+	v.mu.RUnlock()
+	if len(keys) == 0 && kid != "" {
+		// The issuer may have rotated its keys since the last fetch.
+		err = v.refresh(ctx, jwksRefetchInterval)
+		v.mu.RLock()
+		keys = matchKeys(nil, v.remote, kid)
+		v.mu.RUnlock()
+	}
+	return keys, err
+}
+
+func matchKeys(dst, keys []Key, kid string) []Key {
+	for _, k := range keys {
+		if kid == "" || k.ID == kid {
+			dst = append(dst, k)
+		}
+	}
+	return dst
+}
+
+// refresh fetches JWKSURL if the last fetch is older than maxAge. A failed
+// fetch keeps the previous keys.
+func (v *JWTVerifier) refresh(ctx context.Context, maxAge time.Duration) error {
+	v.fetchMu.Lock()
+	defer v.fetchMu.Unlock()
+	if !v.fetchedAt.IsZero() && v.now().Sub(v.fetchedAt) < maxAge {
+		return v.fetchErr
+	}
+
+	keys, err := v.fetch(ctx)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.fetchedAt = v.now()
+	v.fetchErr = err
+	if err == nil {
+		v.remote = keys
+	}
+	return err
+}
+
+func (v *JWTVerifier) fetch(ctx context.Context) ([]Key, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.opt.JWKSURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("auth: fetch JWKS: %w", err)
+	}
+	resp, err := v.opt.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("auth: fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("auth: fetch JWKS: %s", resp.Status)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(resp.Body); err != nil {
+		return nil, fmt.Errorf("auth: fetch JWKS: %w", err)
+	}
+	return ParseJWKS(buf.Bytes())
+}
+
+func decodeSegment(seg string, d any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, d)
+}
+
+func numericDate(v any) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true
+}
+
+// stringList reads a claim holding a string or an array of strings,
+// splitting strings on spaces and commas if split is set.
+func stringList(v any, split bool) []string {
+	switch v := v.(type) {
+	case string:
+		if !split {
+			return []string{v}
+		}
+		return strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func hashOf(alg string) (crypto.Hash, bool) {
+	switch alg[len(alg)-3:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+func curveOf(alg string) elliptic.Curve {
+	switch alg {
+	case "ES256":
+		return elliptic.P256()
+	case "ES384":
+		return elliptic.P384()
+	case "ES512":
+		return elliptic.P521()
+	}
+	return nil
+}
+
+func digest(h crypto.Hash, b []byte) []byte {
+	hh := h.New()
+	hh.Write(b)
+	return hh.Sum(nil)
+}
+
+var errSignature = errors.New("auth: signature mismatch")
+
+func verifySignature(alg string, key any, signed, sig []byte) error {
+	if alg == "EdDSA" {
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(pub, signed, sig) {
+			return errSignature
+		}
+		return nil
+	}
+	if len(alg) != 5 {
+		return errSignature
+	}
+	h, ok := hashOf(alg)
+	if !ok {
+		return errSignature
+	}
+
+	switch alg[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return errSignature
+		}
+		mac := hmac.New(h.New, secret)
+		mac.Write(signed)
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errSignature
+		}
+		return nil
+	case "RS", "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errSignature
+		}
+		if alg[0] == 'R' {
+			return rsa.VerifyPKCS1v15(pub, h, digest(h, signed), sig)
+		}
+		return rsa.VerifyPSS(pub, h, digest(h, signed), sig, nil)
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != curveOf(alg) {
+			return errSignature
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return errSignature
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(pub, digest(h, signed), r, s) {
+			return errSignature
+		}
+		return nil
+	}
+	return errSignature
+}
+
+// SignJWT issues a token with claims, signed by key.Key: a []byte secret
+// for HS*, or an *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey
+// for the alg named by key.Algorithm.
+func SignJWT(key Key, claims map[string]any) (string, error) {
+	header, err := json.Marshal(jwtHeader{Alg: key.Algorithm, Kid: key.ID, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := sign(key.Algorithm, key.Key, []byte(signed))
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func sign(alg string, key any, signed []byte) ([]byte, error) {
+	errKey := fmt.Errorf("auth: %T cannot sign %s", key, alg)
+	if alg == "EdDSA" {
+		priv, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errKey
+		}
+		return ed25519.Sign(priv, signed), nil
+	}
+	h, ok := crypto.Hash(0), false
+	if len(alg) == 5 {
+		h, ok = hashOf(alg)
+	}
+	if !ok {
+		return nil, fmt.Errorf("auth: unsupported algorithm %q", alg)
+	}
+
+	switch alg[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, errKey
+		}
+		mac := hmac.New(h.New, secret)
+		mac.Write(signed)
+		return mac.Sum(nil), nil
+	case "RS", "PS":
+		priv, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errKey
+		}
+		if alg[0] == 'R' {
+			return rsa.SignPKCS1v15(rand.Reader, priv, h, digest(h, signed))
+		}
+		return rsa.SignPSS(rand.Reader, priv, h, digest(h, signed), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case "ES":
+		priv, ok := key.(*ecdsa.PrivateKey)
+		if !ok || priv.Curve != curveOf(alg) {
+			return nil, errKey
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest(h, signed))
+		if err != nil {
+			return nil, err
+		}
+		size := (priv.Curve.Params().BitSize + 7) / 8
+		sig := make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+		return sig, nil
+	}
+	return nil, fmt.Errorf("auth: unsupported algorithm %q", alg)
+}
+
+// ParsePublicKeyPEM parses a PEM encoded PKIX public key or certificate,
+// for use as Key.Key.
+func ParsePublicKeyPEM(b []byte) (any, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("auth: no PEM block found")
+	}
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("auth: parse certificate: %w", err)
+		}
+		return cert.PublicKey, nil
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("auth: parse public key: %w", err)
+	}
+	return pub, nil
+}
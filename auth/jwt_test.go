@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTVerifier_Algorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	secret := []byte("secret")
+
+	tests := []struct {
+		alg       string
+		priv, pub any
+	}{
+		{"HS256", secret, secret},
+		{"HS512", secret, secret},
+		{"RS256", rsaKey, &rsaKey.PublicKey},
+		{"PS384", rsaKey, &rsaKey.PublicKey},
+		{"ES384", ecKey, &ecKey.PublicKey},
+		{"EdDSA", edKey, edPub},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			v, err := NewJWTVerifier(JWTOption{Keys: []Key{{Key: tt.pub}}})
+			require.NoError(t, err)
+			token, err := SignJWT(Key{Algorithm: tt.alg, Key: tt.priv}, map[string]any{"sub": "alice"})
+			require.NoError(t, err)
+
+			p, err := v.Verify(context.Background(), token)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", p.Subject)
+			assert.Equal(t, "jwt", p.Method)
+
+			_, err = v.Verify(context.Background(), token[:len(token)-4]+"AAAA")
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestJWTVerifier_Claims(t *testing.T) {
+	key := Key{Algorithm: "HS256", Key: []byte("secret")}
+	v, err := NewJWTVerifier(JWTOption{
+		Keys:     []Key{key},
+		Issuer:   "https://issuer",
+		Audience: "api",
+		Leeway:   time.Minute,
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	sign := func(claims map[string]any) string {
+		token, err := SignJWT(key, claims)
+		require.NoError(t, err)
+		return token
+	}
+	valid := func() map[string]any {
+		return map[string]any{
+			"sub":   "alice",
+			"iss":   "https://issuer",
+			"aud":   []string{"web", "api"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"roles": []string{"admin"},
+			"scope": "read write",
+		}
+	}
+
+	p, err := v.Verify(context.Background(), sign(valid()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin"}, p.Roles)
+	assert.Equal(t, []string{"read", "write"}, p.Scopes)
+	assert.Equal(t, "https://issuer", p.Claims["iss"])
+
+	claims := valid()
+	claims["exp"] = now.Add(-30 * time.Second).Unix()
+	_, err = v.Verify(context.Background(), sign(claims))
+	assert.NoError(t, err, "within leeway")
+
+	for name, change := range map[string]func(map[string]any){
+		"expired":     func(c map[string]any) { c["exp"] = now.Add(-2 * time.Minute).Unix() },
+		"not yet":     func(c map[string]any) { c["nbf"] = now.Add(2 * time.Minute).Unix() },
+		"issuer":      func(c map[string]any) { c["iss"] = "https://other" },
+		"audience":    func(c map[string]any) { c["aud"] = "web" },
+		"no audience": func(c map[string]any) { c["aud"] = nil },
+	} {
+		claims := valid()
+		change(claims)
+		_, err := v.Verify(context.Background(), sign(claims))
+		assert.ErrorIs(t, err, ErrInvalidCredentials, name)
+	}
+
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`)) + "."
+	_, err = v.Verify(context.Background(), none)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func jwksOf(kid string, pub *rsa.PublicKey) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	return fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":%q,"alg":"RS256","use":"sig","n":%q,"e":%q},{"kty":"RSA","use":"enc","n":"AQAB","e":"AQAB"}]}`,
+		kid, enc(pub.N.Bytes()), enc(big.NewInt(int64(pub.E)).Bytes()))
+}
+
+func TestJWTVerifier_JWKS(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(file, []byte(jwksOf("k1", &key1.PublicKey)), 0o600))
+	v, err := NewJWTVerifier(JWTOption{JWKSFile: file})
+	require.NoError(t, err)
+	token, err := SignJWT(Key{ID: "k1", Algorithm: "RS256", Key: key1}, map[string]any{"sub": "alice"})
+	require.NoError(t, err)
+	_, err = v.Verify(context.Background(), token)
+	assert.NoError(t, err)
+
+	var current atomic.Value
+	current.Store(jwksOf("k1", &key1.PublicKey))
+	var fetches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte(current.Load().(string)))
+	}))
+	defer ts.Close()
+
+	v, err = NewJWTVerifier(JWTOption{JWKSURL: ts.URL})
+	require.NoError(t, err)
+	now := time.Now()
+	v.now = func() time.Time { return now }
+	_, err = v.Verify(context.Background(), token)
+	assert.NoError(t, err)
+	_, err = v.Verify(context.Background(), token)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, fetches.Load())
+
+	// A rotated key is fetched once the refetch interval has passed.
+	current.Store(jwksOf("k2", &key2.PublicKey))
+	rotated, err := SignJWT(Key{ID: "k2", Algorithm: "RS256", Key: key2}, map[string]any{"sub": "bob"})
+	require.NoError(t, err)
+	_, err = v.Verify(context.Background(), rotated)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	now = now.Add(2 * time.Minute)
+	p, err := v.Verify(context.Background(), rotated)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", p.Subject)
+	assert.EqualValues(t, 2, fetches.Load())
+}
@@ -35,6 +35,8 @@ type Config interface {
 	GetDuration(key string) time.Duration
 	GetDurationOrDefault(key string, def time.Duration) time.Duration
 	Get(key string) interface{}
+	Set(key string, value interface{})
+	AllSettings() map[string]interface{}
 }
 
 type FileType uint8
@@ -60,6 +62,7 @@ const (
 )
 
 type config struct {
+	mu                sync.RWMutex
 	kv                map[string]map[string]interface{}
 	kvCache           *sync.Map
 	ignoreFileNameKey bool
@@ -209,6 +212,91 @@ func (c *config) Get(key string) interface{} {
 	return c.get(key)
 }
 
+// Set overrides the value at key, creating intermediate maps as needed, so
+// that both key and its parents observe the new value.
+func (c *config) Set(key string, value interface{}) {
+	keys := strings.Split(strings.ToLower(key), defaultKeyDelim)
+
+	var (
+		fileKey string
+		mapKey  []string
+	)
+	if c.ignoreFileNameKey {
+		fileKey = defaultFileKey
+		mapKey = keys
+	} else {
+		fileKey = keys[0]
+		mapKey = keys[1:]
+	}
+	if len(mapKey) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.kv[fileKey]
+	if !ok {
+		m = make(map[string]interface{})
+		c.kv[fileKey] = m
+	}
+	for _, k := range mapKey[:len(mapKey)-1] {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[k] = next
+		}
+		m = next
+	}
+	m[mapKey[len(mapKey)-1]] = value
+
+	c.kvCache.Range(func(k, _ any) bool {
+		c.kvCache.Delete(k)
+		return true
+	})
+}
+
+// AllSettings returns a copy of every loaded value, keyed by file name
+// unless file names are ignored.
+func (c *config) AllSettings() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.ignoreFileNameKey {
+		return copySettings(c.kv[defaultFileKey])
+	}
+	all := make(map[string]interface{}, len(c.kv))
+	for fileKey, m := range c.kv {
+		all[fileKey] = copySettings(m)
+	}
+	return all
+}
+
+func copySettings(m map[string]interface{}) map[string]interface{} {
+	cp := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		cp[k] = copySetting(v)
+	}
+	return cp
+}
+
+func copySetting(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return copySettings(val)
+	case map[interface{}]interface{}:
+		return copySettings(cast.ToStringMap(val))
+	case []interface{}:
+		cp := make([]interface{}, len(val))
+		for i, item := range val {
+			cp[i] = copySetting(item)
+		}
+		return cp
+	default:
+		return v
+	}
+}
+
 func (c *config) get(key string) interface{} {
 	val, _ := c.getValue(key)
 	return val
@@ -220,6 +308,10 @@ func (c *config) getValue(key string) (interface{}, bool) {
 		return cacheVal, true
 	}
 	keys := strings.Split(lk, defaultKeyDelim)
+	// Caching under the lock keeps Set from clearing the cache between the
+	// lookup and the store, which would leave a stale value behind.
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	val, ok := c.getValueFromMaps(keys)
 	if ok {
 		c.kvCache.Store(lk, val)
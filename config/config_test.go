@@ -40,6 +40,25 @@ func TestConfigYaml(t *testing.T) {
 	testNotIniConfigData(t, conf)
 }
 
+func TestConfigSet(t *testing.T) {
+	conf, err := New(PathTypeFile, false, "./testdata/config.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "test", conf.GetString("config.testData.name"))
+	conf.Set("config.testData.name", "override")
+	conf.Set("server.http.addr", ":9000")
+
+	assert.Equal(t, "override", conf.GetString("config.testData.name"))
+	assert.Equal(t, 102400, conf.GetInt("config.testData.number"))
+	assert.Equal(t, ":9000", conf.GetString("server.http.addr"))
+	assert.Equal(t, map[string]string{"addr": ":9000"}, conf.GetStringMapString("server.http"))
+
+	all := conf.AllSettings()
+	assert.Equal(t, map[string]interface{}{"http": map[string]interface{}{"addr": ":9000"}}, all["server"])
+	all["server"].(map[string]interface{})["http"] = nil
+	assert.Equal(t, ":9000", conf.GetString("server.http.addr"))
+}
+
 func testNotIniConfigData(t *testing.T, conf Config) {
 	var (
 		intSlice     = []int{1, 3, 5, 7}
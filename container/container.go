@@ -0,0 +1,324 @@
+// Package container wires providers together by type. Constructors follow
+// the providers' own shape, e.g. func(config.Config) (db.Provider, func(), error):
+// parameters are resolved from other constructors, and the returned cleanup
+// functions run in reverse construction order on Cleanup.
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	cleanupType = reflect.TypeOf(func() {})
+)
+
+type constructor struct {
+	fn      reflect.Value
+	params  []reflect.Type
+	out     reflect.Type
+	value   reflect.Value
+	built   bool
+	cleanup func()
+}
+
+type Container struct {
+	mu       sync.Mutex
+	ctors    map[reflect.Type]*constructor
+	order    []reflect.Type
+	cleanups []func()
+}
+
+func New() *Container {
+	return &Container{
+		ctors: make(map[reflect.Type]*constructor),
+	}
+}
+
+// Provide registers a constructor. It must be a function returning the
+// provided value, optionally followed by a cleanup func() and/or an error.
+// Variadic parameters, such as option lists, are left empty.
+func (c *Container) Provide(ctor any) error {
+	fn := reflect.ValueOf(ctor)
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return fmt.Errorf("container: constructor must be a function, got %T", ctor)
+	}
+
+	ft := fn.Type()
+	if err := checkResults(ft); err != nil {
+		return err
+	}
+
+	params := make([]reflect.Type, 0, ft.NumIn())
+	for i := 0; i < ft.NumIn(); i++ {
+		if ft.IsVariadic() && i == ft.NumIn()-1 {
+			break
+		}
+		params = append(params, ft.In(i))
+	}
+
+	out := ft.Out(0)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.ctors[out]; ok {
+		return fmt.Errorf("container: %s already provided", out)
+	}
+	c.ctors[out] = &constructor{fn: fn, params: params, out: out}
+	c.order = append(c.order, out)
+	return nil
+}
+
+func checkResults(ft reflect.Type) error {
+	n := ft.NumOut()
+	if n == 0 || n > 3 {
+		return fmt.Errorf("container: constructor %s must return 1 to 3 values", ft)
+	}
+	if ft.Out(0) == errorType || ft.Out(0) == cleanupType {
+		return fmt.Errorf("container: constructor %s must return a value first", ft)
+	}
+	rest := make([]reflect.Type, 0, 2)
+	for i := 1; i < n; i++ {
+		rest = append(rest, ft.Out(i))
+	}
+	switch {
+	case len(rest) == 0:
+	case len(rest) == 1 && (rest[0] == errorType || rest[0] == cleanupType):
+	case len(rest) == 2 && rest[0] == cleanupType && rest[1] == errorType:
+	default:
+		return fmt.Errorf("container: constructor %s must return (T), (T, error), (T, func()) or (T, func(), error)", ft)
+	}
+	return nil
+}
+
+// MustProvide is like Provide but panics on error.
+func (c *Container) MustProvide(ctors ...any) {
+	for _, ctor := range ctors {
+		if err := c.Provide(ctor); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Invoke calls fn with its parameters resolved from the container. If fn
+// returns an error as its last result, it is returned.
+func (c *Container) Invoke(fn any) error {
+	fv := reflect.ValueOf(fn)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return fmt.Errorf("container: Invoke expects a function, got %T", fn)
+	}
+	ft := fv.Type()
+
+	c.mu.Lock()
+	args := make([]reflect.Value, 0, ft.NumIn())
+	for i := 0; i < ft.NumIn(); i++ {
+		if ft.IsVariadic() && i == ft.NumIn()-1 {
+			break
+		}
+		v, err := c.resolve(ft.In(i), nil)
+		if err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		args = append(args, v)
+	}
+	c.mu.Unlock()
+
+	results := fv.Call(args)
+	if n := len(results); n > 0 && ft.Out(n-1) == errorType {
+		if err, _ := results[n-1].Interface().(error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resolve returns the value of type T, constructing it and its
+// dependencies on first use.
+func Resolve[T any](c *Container) (T, error) {
+	var zero T
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, err := c.resolve(reflect.TypeOf((*T)(nil)).Elem(), nil)
+	if err != nil {
+		return zero, err
+	}
+	// A nil interface value has no dynamic type to assert.
+	t, _ := v.Interface().(T)
+	return t, nil
+}
+
+func MustResolve[T any](c *Container) T {
+	v, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (c *Container) resolve(t reflect.Type, path []reflect.Type) (reflect.Value, error) {
+	for i, p := range path {
+		if p == t {
+			return reflect.Value{}, &CycleError{Path: append(append([]reflect.Type(nil), path[i:]...), t)}
+		}
+	}
+
+	ctor, ok := c.ctors[t]
+	if !ok {
+		if len(path) == 0 {
+			return reflect.Value{}, fmt.Errorf("container: no constructor for %s", t)
+		}
+		return reflect.Value{}, fmt.Errorf("container: no constructor for %s, required by %s", t, path[len(path)-1])
+	}
+	if ctor.built {
+		return ctor.value, nil
+	}
+
+	path = append(path, t)
+	args := make([]reflect.Value, len(ctor.params))
+	for i, p := range ctor.params {
+		v, err := c.resolve(p, path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		args[i] = v
+	}
+
+	results := ctor.fn.Call(args)
+	var (
+		cleanup func()
+		err     error
+	)
+	for _, r := range results[1:] {
+		switch r.Type() {
+		case errorType:
+			err, _ = r.Interface().(error)
+		case cleanupType:
+			cleanup, _ = r.Interface().(func())
+		}
+	}
+	if err != nil {
+		// A constructor may return a cleanup for what it built before failing.
+		if cleanup != nil {
+			cleanup()
+		}
+		return reflect.Value{}, fmt.Errorf("container: constructing %s: %w", t, err)
+	}
+
+	ctor.value = results[0]
+	ctor.built = true
+	ctor.cleanup = cleanup
+	if ctor.cleanup != nil {
+		c.cleanups = append(c.cleanups, ctor.cleanup)
+	}
+	return ctor.value, nil
+}
+
+// Cleanup runs the cleanups of every constructed value in reverse
+// construction order. It is safe to call more than once.
+func (c *Container) Cleanup() {
+	c.mu.Lock()
+	cleanups := c.cleanups
+	c.cleanups = nil
+	c.mu.Unlock()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+// Validate reports missing constructors and dependency cycles without
+// constructing anything.
+func (c *Container) Validate() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	var (
+		errs  []error
+		state = make(map[reflect.Type]int)
+		path  []reflect.Type
+		visit func(t reflect.Type)
+	)
+	visit = func(t reflect.Type) {
+		switch state[t] {
+		case visited:
+			return
+		case visiting:
+			for i, p := range path {
+				if p == t {
+					errs = append(errs, &CycleError{Path: append(append([]reflect.Type(nil), path[i:]...), t)})
+					break
+				}
+			}
+			return
+		}
+		ctor, ok := c.ctors[t]
+		if !ok {
+			errs = append(errs, fmt.Errorf("container: no constructor for %s, required by %s", t, path[len(path)-1]))
+			state[t] = visited
+			return
+		}
+		state[t] = visiting
+		path = append(path, t)
+		for _, p := range ctor.params {
+			visit(p)
+		}
+		path = path[:len(path)-1]
+		state[t] = visited
+	}
+	for _, t := range c.order {
+		visit(t)
+	}
+	return errors.Join(errs...)
+}
+
+// Graph returns the dependency graph in Graphviz DOT format, with an edge
+// from every type to each of its dependencies.
+func (c *Container) Graph() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		b     strings.Builder
+		edges []string
+	)
+	b.WriteString("digraph container {\n")
+	for _, t := range c.order {
+		ctor := c.ctors[t]
+		attrs := ""
+		if ctor.built {
+			attrs = " [style=bold]"
+		}
+		fmt.Fprintf(&b, "\t%q%s;\n", t.String(), attrs)
+		for _, p := range ctor.params {
+			edges = append(edges, fmt.Sprintf("\t%q -> %q;\n", t.String(), p.String()))
+		}
+	}
+	sort.Strings(edges)
+	for _, e := range edges {
+		b.WriteString(e)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+type CycleError struct {
+	Path []reflect.Type
+}
+
+func (e *CycleError) Error() string {
+	names := make([]string, len(e.Path))
+	for i, t := range e.Path {
+		names[i] = t.String()
+	}
+	return "container: dependency cycle: " + strings.Join(names, " -> ")
+}
@@ -0,0 +1,95 @@
+package container
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	testConfig   struct{ name string }
+	testDB       struct{ conf *testConfig }
+	testServer   struct{ db *testDB }
+	testOption   func(*testServer)
+	testCycleA   struct{}
+	testCycleB   struct{}
+	testNotFound struct{}
+)
+
+func TestContainer_ResolveAndCleanup(t *testing.T) {
+	var calls []string
+	c := New()
+	c.MustProvide(
+		func(db *testDB, opts ...testOption) *testServer {
+			calls = append(calls, "server")
+			return &testServer{db: db}
+		},
+		func(conf *testConfig) (*testDB, func(), error) {
+			calls = append(calls, "db")
+			return &testDB{conf: conf}, func() { calls = append(calls, "close db") }, nil
+		},
+		func() (*testConfig, func()) {
+			calls = append(calls, "config")
+			return &testConfig{name: "app"}, func() { calls = append(calls, "close config") }
+		},
+	)
+	require.NoError(t, c.Validate())
+
+	srv, err := Resolve[*testServer](c)
+	require.NoError(t, err)
+	assert.Equal(t, "app", srv.db.conf.name)
+	assert.Same(t, srv, MustResolve[*testServer](c))
+
+	require.NoError(t, c.Invoke(func(db *testDB) error {
+		assert.Same(t, srv.db, db)
+		return nil
+	}))
+
+	c.Cleanup()
+	c.Cleanup()
+	assert.Equal(t, []string{"config", "db", "server", "close db", "close config"}, calls)
+	assert.Contains(t, c.Graph(), `"*container.testServer" -> "*container.testDB";`)
+}
+
+func TestContainer_Errors(t *testing.T) {
+	var closed bool
+	c := New()
+	c.MustProvide(
+		func(*testCycleB) *testCycleA { return nil },
+		func(*testCycleA) *testCycleB { return nil },
+		func(*testNotFound) *testConfig { return nil },
+		func() (*testDB, error) { return nil, stdErrors.New("refused") },
+		func() (*testServer, func(), error) {
+			return nil, func() { closed = true }, stdErrors.New("refused")
+		},
+		func() fmt.Stringer { return nil },
+	)
+
+	_, err := Resolve[*testCycleA](c)
+	assert.EqualError(t, err, "container: dependency cycle: *container.testCycleA -> *container.testCycleB -> *container.testCycleA")
+
+	_, err = Resolve[*testConfig](c)
+	assert.EqualError(t, err, "container: no constructor for *container.testNotFound, required by *container.testConfig")
+
+	_, err = Resolve[*testDB](c)
+	assert.EqualError(t, err, "container: constructing *container.testDB: refused")
+
+	_, err = Resolve[*testServer](c)
+	assert.Error(t, err)
+	assert.True(t, closed)
+
+	s, err := Resolve[fmt.Stringer](c)
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	var cycle *CycleError
+	assert.ErrorAs(t, c.Validate(), &cycle)
+
+	assert.Error(t, c.Provide(func() *testDB { return nil }))
+	assert.Error(t, c.Provide(func() error { return nil }))
+	assert.Error(t, c.Provide(func() (*testServer, error, func()) { return nil, nil, nil }))
+	assert.Error(t, c.Provide("not a func"))
+}
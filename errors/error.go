@@ -29,3 +29,7 @@ func (e *Errors) Error() string {
 func (e *Errors) Code() int {
 	return e.code
 }
+
+func (e *Errors) Message() string {
+	return e.msg
+}
@@ -0,0 +1,231 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyper-micro/hyper/internal/json"
+)
+
+type Kind uint8
+
+const (
+	Readiness Kind = 1 << iota
+	Liveness
+
+	All = Readiness | Liveness
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+const defaultCheckTimeout = 5 * time.Second
+
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Err returns an error naming every failed check, or nil if all passed.
+func (r Report) Err() error {
+	if r.OK() {
+		return nil
+	}
+	var failed []string
+	for name, result := range r.Checks {
+		if result.Status != StatusOK {
+			failed = append(failed, fmt.Sprintf("%s: %s", name, result.Error))
+		}
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("health: %s", strings.Join(failed, "; "))
+}
+
+type check struct {
+	kind    Kind
+	checker Checker
+}
+
+type Registry struct {
+	mu      sync.RWMutex
+	checks  map[string]check
+	Timeout time.Duration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		checks:  make(map[string]check),
+		Timeout: defaultCheckTimeout,
+	}
+}
+
+// Register adds a named checker, replacing any checker with the same name.
+func (r *Registry) Register(name string, kind Kind, c Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check{kind: kind, checker: c}
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.checks[name]
+	return ok
+}
+
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckOne runs the named checker. Unknown names are reported as healthy.
+func (r *Registry) CheckOne(ctx context.Context, name string) error {
+	r.mu.RLock()
+	c, ok := r.checks[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return r.run(ctx, c.checker)
+}
+
+// Check runs every checker of the given kind concurrently.
+func (r *Registry) Check(ctx context.Context, kind Kind) Report {
+	r.mu.RLock()
+	checks := make(map[string]Checker, len(r.checks))
+	for name, c := range r.checks {
+		if c.kind&kind != 0 {
+			checks[name] = c.checker
+		}
+	}
+	r.mu.RUnlock()
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		report = Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+	)
+	for name, c := range checks {
+		wg.Add(1)
+		go func(name string, c Checker) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := r.run(ctx, c)
+			result := CheckResult{
+				Status:   StatusOK,
+				Duration: time.Since(start).String(),
+			}
+			if err != nil {
+				result.Status = StatusFail
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = StatusFail
+			}
+		}(name, c)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c Checker) error {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	return c.Check(ctx)
+}
+
+// Handler serves the report for kind as JSON, answering 503 when any
+// check fails.
+func (r *Registry) Handler(kind Kind) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context(), kind)
+		b, err := json.Marshal(report)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		code := http.StatusOK
+		if !report.OK() {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		_, _ = w.Write(b)
+	})
+}
+
+var registry atomic.Pointer[Registry]
+
+func init() {
+	SetDefault(NewRegistry())
+}
+
+// SetDefault replaces the registry used by the package-level functions.
+// A nil registry is ignored.
+func SetDefault(r *Registry) {
+	if r == nil {
+		return
+	}
+	registry.Store(r)
+}
+
+func Default() *Registry {
+	return registry.Load()
+}
+
+func Register(name string, kind Kind, c Checker) {
+	Default().Register(name, kind, c)
+}
+
+func Unregister(name string) {
+	Default().Unregister(name)
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyper-micro/hyper/internal/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	r := NewRegistry()
+	r.Register("db", Readiness, CheckerFunc(func(context.Context) error {
+		return stdErrors.New("connection refused")
+	}))
+	r.Register("ping", Liveness, CheckerFunc(func(context.Context) error {
+		return nil
+	}))
+
+	live := r.Check(context.Background(), Liveness)
+	assert.True(t, live.OK())
+	assert.Len(t, live.Checks, 1)
+
+	ready := r.Check(context.Background(), Readiness)
+	assert.False(t, ready.OK())
+	assert.Equal(t, "connection refused", ready.Checks["db"].Error)
+	assert.EqualError(t, ready.Err(), "health: db: connection refused")
+	assert.NoError(t, live.Err())
+
+	assert.Len(t, r.Check(context.Background(), All).Checks, 2)
+	assert.Error(t, r.CheckOne(context.Background(), "db"))
+	assert.NoError(t, r.CheckOne(context.Background(), "unknown"))
+
+	r.Unregister("db")
+	assert.Equal(t, []string{"ping"}, r.Names())
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.Register("redis", Readiness, CheckerFunc(func(context.Context) error {
+		return stdErrors.New("timeout")
+	}))
+
+	w := httptest.NewRecorder()
+	r.Handler(Readiness).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusFail, report.Checks["redis"].Status)
+
+	w = httptest.NewRecorder()
+	r.Handler(Liveness).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
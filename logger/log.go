@@ -4,6 +4,7 @@ import (
 	"io"
 	"slices"
 	"strings"
+	"sync/atomic"
 
 	"github.com/hyper-micro/hyper/logger/writer"
 )
@@ -17,6 +18,8 @@ type Logger interface {
 	Infof(format string, args ...interface{})
 	Warnf(format string, args ...interface{})
 	Errorf(format string, args ...interface{})
+	Named(name string) Logger
+	With(keysAndValues ...interface{}) Logger
 }
 
 type Level int8
@@ -36,6 +39,20 @@ var unmarshalLevelText = map[string]Level{
 	"error": ErrorLevel,
 }
 
+var levelText = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+}
+
+func (l Level) String() string {
+	if text, ok := levelText[l]; ok {
+		return text
+	}
+	return "none"
+}
+
 func ParseLevel(text string) Level {
 	text = strings.ToLower(text)
 	lvl, _ := unmarshalLevelText[text]
@@ -89,39 +106,62 @@ func NewLogger(conf Config) Logger {
 	return driver
 }
 
-var logger = NewLogger(Config{
-	Level:   "debug",
-	Encoder: "console",
-})
+var logger atomic.Pointer[Logger]
+
+func init() {
+	SetDefault(NewLogger(Config{
+		Level:   "debug",
+		Encoder: "console",
+	}))
+}
+
+// SetDefault replaces the logger used by the package-level functions.
+// A nil logger is ignored.
+func SetDefault(l Logger) {
+	if l == nil {
+		return
+	}
+	logger.Store(&l)
+}
+
+// Default returns the logger used by the package-level functions.
+func Default() Logger {
+	return *logger.Load()
+}
+
+// Named returns a child of the default logger with name appended to its name.
+func Named(name string) Logger {
+	return Default().Named(name)
+}
 
 func Debug(args ...interface{}) {
-	logger.Debug(args...)
+	Default().Debug(args...)
 }
 
 func Info(args ...interface{}) {
-	logger.Info(args...)
+	Default().Info(args...)
 }
 
 func Warn(args ...interface{}) {
-	logger.Warn(args...)
+	Default().Warn(args...)
 }
 
 func Error(args ...interface{}) {
-	logger.Error(args...)
+	Default().Error(args...)
 }
 
 func Debugf(format string, args ...interface{}) {
-	logger.Debugf(format, args...)
+	Default().Debugf(format, args...)
 }
 
 func Infof(format string, args ...interface{}) {
-	logger.Infof(format, args...)
+	Default().Infof(format, args...)
 }
 
 func Warnf(format string, args ...interface{}) {
-	logger.Warnf(format, args...)
+	Default().Warnf(format, args...)
 }
 
 func Errorf(format string, args ...interface{}) {
-	logger.Errorf(format, args...)
+	Default().Errorf(format, args...)
 }
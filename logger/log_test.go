@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/hyper-micro/hyper/internal/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBufferLogger(buf *bytes.Buffer) Logger {
+	return NewZapLogger(ZapLoggerConfig{Level: "debug", Writer: []io.Writer{buf}, Encoder: EncoderJSON})
+}
+
+func TestSetDefault(t *testing.T) {
+	prev := Default()
+	var buf bytes.Buffer
+	l := newBufferLogger(&buf)
+
+	SetDefault(l)
+	SetDefault(nil)
+	assert.Equal(t, l, Default())
+	Infof("hello %s", "world")
+	assert.Contains(t, buf.String(), `"msg":"hello world"`)
+
+	SetDefault(prev)
+	buf.Reset()
+	Info("elsewhere")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, prev, Default())
+}
+
+func TestNamed(t *testing.T) {
+	prev := Default()
+	defer SetDefault(prev)
+	var buf bytes.Buffer
+	SetDefault(newBufferLogger(&buf))
+
+	Named("server").Named("http").With("addr", ":8080").Warn("listen")
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "server.http", entry["logger"])
+	assert.Equal(t, ":8080", entry["addr"])
+	assert.Equal(t, "listen", entry["msg"])
+
+	// Children do not change their parent.
+	buf.Reset()
+	Default().Error("plain")
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.NotContains(t, buf.String(), `"logger"`)
+	assert.NotContains(t, buf.String(), `"addr"`)
+}
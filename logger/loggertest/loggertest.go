@@ -0,0 +1,257 @@
+// Package loggertest provides an in-memory logger.Logger that records every
+// entry so tests can assert on log output without capturing stdout.
+package loggertest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hyper-micro/hyper/logger"
+)
+
+type Entry struct {
+	Level   logger.Level
+	Name    string
+	Message string
+	Fields  map[string]interface{}
+}
+
+func (e Entry) String() string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(e.Level.String()))
+	if e.Name != "" {
+		b.WriteString("\t")
+		b.WriteString(e.Name)
+	}
+	b.WriteString("\t")
+	b.WriteString(e.Message)
+	if len(e.Fields) > 0 {
+		b.WriteString("\t")
+		b.WriteString(fmt.Sprint(e.Fields))
+	}
+	return b.String()
+}
+
+type Entries []Entry
+
+func (es Entries) filter(f func(Entry) bool) Entries {
+	var out Entries
+	for _, e := range es {
+		if f(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (es Entries) FilterLevel(lvl logger.Level) Entries {
+	return es.filter(func(e Entry) bool {
+		return e.Level == lvl
+	})
+}
+
+func (es Entries) FilterName(name string) Entries {
+	return es.filter(func(e Entry) bool {
+		return e.Name == name
+	})
+}
+
+func (es Entries) FilterMessage(msg string) Entries {
+	return es.filter(func(e Entry) bool {
+		return e.Message == msg
+	})
+}
+
+func (es Entries) FilterMessageSnippet(snippet string) Entries {
+	return es.filter(func(e Entry) bool {
+		return strings.Contains(e.Message, snippet)
+	})
+}
+
+func (es Entries) FilterField(key string, value interface{}) Entries {
+	return es.filter(func(e Entry) bool {
+		v, ok := e.Fields[key]
+		return ok && reflect.DeepEqual(v, value)
+	})
+}
+
+func (es Entries) FilterFieldKey(key string) Entries {
+	return es.filter(func(e Entry) bool {
+		_, ok := e.Fields[key]
+		return ok
+	})
+}
+
+func (es Entries) Messages() []string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+func (es Entries) String() string {
+	lines := make([]string, 0, len(es))
+	for _, e := range es {
+		lines = append(lines, e.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type recorder struct {
+	mu      sync.RWMutex
+	entries Entries
+}
+
+// Logger is an observable logger.Logger. Child loggers created with Named
+// and With share the parent's recorded entries.
+type Logger struct {
+	name   string
+	fields map[string]interface{}
+	rec    *recorder
+}
+
+var _ logger.Logger = (*Logger)(nil)
+
+func New() *Logger {
+	return &Logger{
+		rec: new(recorder),
+	}
+}
+
+func (l *Logger) log(lvl logger.Level, msg string) {
+	var fields map[string]interface{}
+	if len(l.fields) > 0 {
+		fields = make(map[string]interface{}, len(l.fields))
+		for k, v := range l.fields {
+			fields[k] = v
+		}
+	}
+
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	l.rec.entries = append(l.rec.entries, Entry{
+		Level:   lvl,
+		Name:    l.name,
+		Message: msg,
+		Fields:  fields,
+	})
+}
+
+func (l *Logger) Debug(args ...interface{}) {
+	l.log(logger.DebugLevel, fmt.Sprint(args...))
+}
+
+func (l *Logger) Info(args ...interface{}) {
+	l.log(logger.InfoLevel, fmt.Sprint(args...))
+}
+
+func (l *Logger) Warn(args ...interface{}) {
+	l.log(logger.WarnLevel, fmt.Sprint(args...))
+}
+
+func (l *Logger) Error(args ...interface{}) {
+	l.log(logger.ErrorLevel, fmt.Sprint(args...))
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log(logger.DebugLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(logger.InfoLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.log(logger.WarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(logger.ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Named(name string) logger.Logger {
+	if name == "" {
+		return l
+	}
+	child := *l
+	if child.name == "" {
+		child.name = name
+	} else {
+		child.name = child.name + "." + name
+	}
+	return &child
+}
+
+// With returns a child logger with the key-value pairs added to its fields.
+// A trailing key without a value is stored under "!BADKEY", as zap does.
+func (l *Logger) With(keysAndValues ...interface{}) logger.Logger {
+	child := *l
+	child.fields = make(map[string]interface{}, len(l.fields)+len(keysAndValues)/2)
+	for k, v := range l.fields {
+		child.fields[k] = v
+	}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			child.fields["!BADKEY"] = keysAndValues[i]
+			break
+		}
+		child.fields[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+	return &child
+}
+
+// Entries returns a copy of all entries recorded so far.
+func (l *Logger) Entries() Entries {
+	l.rec.mu.RLock()
+	defer l.rec.mu.RUnlock()
+	return append(Entries(nil), l.rec.entries...)
+}
+
+// TakeAll returns all recorded entries and resets the recorder.
+func (l *Logger) TakeAll() Entries {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	entries := l.rec.entries
+	l.rec.entries = nil
+	return entries
+}
+
+func (l *Logger) Len() int {
+	l.rec.mu.RLock()
+	defer l.rec.mu.RUnlock()
+	return len(l.rec.entries)
+}
+
+func (l *Logger) Reset() {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	l.rec.entries = nil
+}
+
+// AssertLogged reports a test error unless an entry of the given level
+// contains snippet in its message.
+func (l *Logger) AssertLogged(t testing.TB, lvl logger.Level, snippet string) bool {
+	t.Helper()
+	entries := l.Entries()
+	if len(entries.FilterLevel(lvl).FilterMessageSnippet(snippet)) > 0 {
+		return true
+	}
+	t.Errorf("loggertest: no %s entry containing %q, recorded:\n%s", lvl, snippet, entries)
+	return false
+}
+
+// AssertNotLogged reports a test error if an entry of the given level
+// contains snippet in its message.
+func (l *Logger) AssertNotLogged(t testing.TB, lvl logger.Level, snippet string) bool {
+	t.Helper()
+	matched := l.Entries().FilterLevel(lvl).FilterMessageSnippet(snippet)
+	if len(matched) == 0 {
+		return true
+	}
+	t.Errorf("loggertest: unexpected %s entry containing %q:\n%s", lvl, snippet, matched)
+	return false
+}
@@ -0,0 +1,39 @@
+package loggertest
+
+import (
+	"testing"
+
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_Record(t *testing.T) {
+	l := New()
+	l.Info("hello")
+	l.Errorf("failed: %d", 42)
+
+	entries := l.Entries()
+	assert.Equal(t, 2, l.Len())
+	assert.Equal(t, []string{"hello", "failed: 42"}, entries.Messages())
+	assert.Len(t, entries.FilterLevel(logger.ErrorLevel), 1)
+	assert.Len(t, entries.FilterMessageSnippet("fail"), 1)
+
+	l.AssertLogged(t, logger.InfoLevel, "hello")
+	l.AssertNotLogged(t, logger.WarnLevel, "hello")
+}
+
+func TestLogger_NamedWith(t *testing.T) {
+	l := New()
+	child := l.Named("server").Named("http").With("addr", ":8080", "tags", []string{"a"}, "dangling")
+	child.Warn("listen")
+
+	entries := l.TakeAll()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "server.http", entries[0].Name)
+	assert.Equal(t, ":8080", entries[0].Fields["addr"])
+	assert.Equal(t, "dangling", entries[0].Fields["!BADKEY"])
+	assert.Len(t, entries.FilterField("addr", ":8080"), 1)
+	assert.Len(t, entries.FilterField("tags", []string{"a"}), 1)
+	assert.Len(t, entries.FilterName("server"), 0)
+	assert.Equal(t, 0, l.Len())
+}
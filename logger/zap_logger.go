@@ -115,3 +115,15 @@ func (l *zapLogger) Warnf(format string, args ...interface{}) {
 func (l *zapLogger) Errorf(format string, args ...interface{}) {
 	l.z().Errorf(format, args...)
 }
+
+func (l *zapLogger) Named(name string) Logger {
+	return &zapLogger{
+		zap: l.z().Named(name),
+	}
+}
+
+func (l *zapLogger) With(keysAndValues ...interface{}) Logger {
+	return &zapLogger{
+		zap: l.z().With(keysAndValues...),
+	}
+}
@@ -0,0 +1,325 @@
+// Package metrics provides counters, gauges and histograms with labels,
+// exposed in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type Type string
+
+const (
+	CounterType   Type = "counter"
+	GaugeType     Type = "gauge"
+	HistogramType Type = "histogram"
+)
+
+// DefBuckets are the default histogram buckets, in seconds, suited to
+// request latencies.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type metric interface {
+	desc() *desc
+	write(b *strings.Builder)
+}
+
+type desc struct {
+	name    string
+	help    string
+	typ     Type
+	labels  []string
+	buckets []float64
+}
+
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]metric),
+	}
+}
+
+// NewCounter registers a counter. Registering the same name and labels
+// again returns the existing counter, so that several servers can share
+// one metric; a conflicting registration panics.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	d := &desc{name: name, help: help, typ: CounterType, labels: labels}
+	return r.register(d, func() metric {
+		return &Counter{vec: newVec[*value](d, newValue)}
+	}).(*Counter)
+}
+
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	d := &desc{name: name, help: help, typ: GaugeType, labels: labels}
+	return r.register(d, func() metric {
+		return &Gauge{vec: newVec[*value](d, newValue)}
+	}).(*Gauge)
+}
+
+// NewHistogram registers a histogram with the given upper bucket bounds,
+// or DefBuckets if none are given.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	d := &desc{name: name, help: help, typ: HistogramType, labels: labels, buckets: buckets}
+	return r.register(d, func() metric {
+		return &Histogram{vec: newVec[*histogramValue](d, func() *histogramValue {
+			return &histogramValue{counts: make([]uint64, len(buckets))}
+		})}
+	}).(*Histogram)
+}
+
+func (r *Registry) register(d *desc, create func() metric) metric {
+	if !validName(d.name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", d.name))
+	}
+	for _, l := range d.labels {
+		if !validName(l) || l == "le" {
+			panic(fmt.Sprintf("metrics: invalid label name %q for %s", l, d.name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.metrics[d.name]; ok {
+		existing := m.desc()
+		if existing.typ != d.typ || strings.Join(existing.labels, ",") != strings.Join(d.labels, ",") {
+			panic(fmt.Sprintf("metrics: %s already registered as %s with labels %v", d.name, existing.typ, existing.labels))
+		}
+		if !slices.Equal(existing.buckets, d.buckets) {
+			panic(fmt.Sprintf("metrics: %s already registered with buckets %v", d.name, existing.buckets))
+		}
+		return m
+	}
+	m := create()
+	r.metrics[d.name] = m
+	return m
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.metrics, name)
+}
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || c == ':':
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+type series[V any] struct {
+	labelValues []string
+	value       V
+}
+
+type vec[V any] struct {
+	d      *desc
+	mu     sync.RWMutex
+	series map[string]*series[V]
+	newV   func() V
+}
+
+func newVec[V any](d *desc, newV func() V) *vec[V] {
+	return &vec[V]{
+		d:      d,
+		series: make(map[string]*series[V]),
+		newV:   newV,
+	}
+}
+
+func (v *vec[V]) desc() *desc {
+	return v.d
+}
+
+func (v *vec[V]) with(labelValues []string) V {
+	if len(labelValues) != len(v.d.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.d.name, len(v.d.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.RLock()
+	s, ok := v.series[key]
+	v.mu.RUnlock()
+	if ok {
+		return s.value
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if s, ok = v.series[key]; !ok {
+		s = &series[V]{labelValues: append([]string(nil), labelValues...), value: v.newV()}
+		v.series[key] = s
+	}
+	return s.value
+}
+
+// sorted returns the series ordered by label values.
+func (v *vec[V]) sorted() []*series[V] {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]*series[V], len(keys))
+	for i, k := range keys {
+		list[i] = v.series[k]
+	}
+	return list
+}
+
+type value struct {
+	bits atomic.Uint64
+}
+
+func newValue() *value {
+	return new(value)
+}
+
+func (v *value) add(delta float64) {
+	for {
+		old := v.bits.Load()
+		if v.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+func (v *value) set(f float64) {
+	v.bits.Store(math.Float64bits(f))
+}
+
+func (v *value) get() float64 {
+	return math.Float64frombits(v.bits.Load())
+}
+
+type Counter struct {
+	*vec[*value]
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.with(labelValues).add(1)
+}
+
+// Add increases the counter by delta, which must not be negative.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.d.name))
+	}
+	c.with(labelValues).add(delta)
+}
+
+func (c *Counter) Value(labelValues ...string) float64 {
+	return c.with(labelValues).get()
+}
+
+type Gauge struct {
+	*vec[*value]
+}
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.with(labelValues).set(v)
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.with(labelValues).add(1)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.with(labelValues).add(-1)
+}
+
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.with(labelValues).add(delta)
+}
+
+func (g *Gauge) Value(labelValues ...string) float64 {
+	return g.with(labelValues).get()
+}
+
+type histogramValue struct {
+	mu     sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+type Histogram struct {
+	*vec[*histogramValue]
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	hv := h.with(labelValues)
+	i := sort.SearchFloat64s(h.d.buckets, v)
+
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+	if i < len(hv.counts) {
+		hv.counts[i]++
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// Count returns the number of observations and their sum.
+func (h *Histogram) Count(labelValues ...string) (count uint64, sum float64) {
+	hv := h.with(labelValues)
+	hv.mu.Lock()
+	defer hv.mu.Unlock()
+	return hv.count, hv.sum
+}
+
+var registry atomic.Pointer[Registry]
+
+func init() {
+	SetDefault(NewRegistry())
+}
+
+// SetDefault replaces the registry used by the package-level functions.
+// A nil registry is ignored.
+func SetDefault(r *Registry) {
+	if r == nil {
+		return
+	}
+	registry.Store(r)
+}
+
+func Default() *Registry {
+	return registry.Load()
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default().NewCounter(name, help, labels...)
+}
+
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default().NewGauge(name, help, labels...)
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default().NewHistogram(name, help, buckets, labels...)
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests served.", "method", "code")
+	requests.Inc("GET", "200")
+	requests.Add(2, "GET", "200")
+	requests.Inc("POST", "500")
+	assert.Same(t, requests, r.NewCounter("requests_total", "Requests served.", "method", "code"))
+
+	conns := r.NewGauge("connections", "Open connections.\nPer server.")
+	conns.Inc()
+	conns.Inc()
+	conns.Dec()
+
+	latency := r.NewHistogram("latency_seconds", "", []float64{0.5, 0.1}, "path")
+	latency.Observe(0.05, `/a"b`)
+	latency.Observe(0.3, `/a"b`)
+	latency.Observe(2, `/a"b`)
+
+	var b strings.Builder
+	assert.NoError(t, r.WriteText(&b))
+	assert.Equal(t, `# HELP connections Open connections.\nPer server.
+# TYPE connections gauge
+connections 1
+# TYPE latency_seconds histogram
+latency_seconds_bucket{path="/a\"b",le="0.1"} 1
+latency_seconds_bucket{path="/a\"b",le="0.5"} 2
+latency_seconds_bucket{path="/a\"b",le="+Inf"} 3
+latency_seconds_sum{path="/a\"b"} 2.35
+latency_seconds_count{path="/a\"b"} 3
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{method="GET",code="200"} 3
+requests_total{method="POST",code="500"} 1
+`, b.String())
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, ContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, b.String(), rec.Body.String())
+}
+
+func TestRegistry_Misuse(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("hits_total", "", "path")
+
+	assert.Panics(t, func() { r.NewGauge("hits_total", "", "path") })
+	assert.Panics(t, func() { r.NewCounter("hits_total", "", "route") })
+	assert.Panics(t, func() { r.NewCounter("bad-name", "") })
+	assert.Panics(t, func() { r.NewHistogram("h", "", nil, "le") })
+	assert.Panics(t, func() { c.Inc() })
+	assert.Panics(t, func() { c.Add(-1, "/") })
+
+	h := r.NewHistogram("latency_seconds", "", []float64{1, 0.5})
+	assert.Same(t, h, r.NewHistogram("latency_seconds", "", []float64{0.5, 1}))
+	assert.Panics(t, func() { r.NewHistogram("latency_seconds", "", []float64{0.5, 1, 2}) })
+	assert.Panics(t, func() { r.NewHistogram("latency_seconds", "", nil) })
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes every metric in the Prometheus text exposition format,
+// ordered by name.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]metric, len(names))
+	for i, name := range names {
+		list[i] = r.metrics[name]
+	}
+	r.mu.RUnlock()
+
+	var b strings.Builder
+	for _, m := range list {
+		d := m.desc()
+		if d.help != "" {
+			b.WriteString("# HELP " + d.name + " " + helpEscaper.Replace(d.help) + "\n")
+		}
+		b.WriteString("# TYPE " + d.name + " " + string(d.typ) + "\n")
+		m.write(&b)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.WriteText(w)
+	})
+}
+
+func Handler() http.Handler {
+	return Default().Handler()
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func (c *Counter) write(b *strings.Builder) {
+	for _, s := range c.sorted() {
+		writeSample(b, c.d.name, c.d.labels, s.labelValues, "", "", s.value.get())
+	}
+}
+
+func (g *Gauge) write(b *strings.Builder) {
+	for _, s := range g.sorted() {
+		writeSample(b, g.d.name, g.d.labels, s.labelValues, "", "", s.value.get())
+	}
+}
+
+func (h *Histogram) write(b *strings.Builder) {
+	for _, s := range h.sorted() {
+		s.value.mu.Lock()
+		var cumulative uint64
+		for i, bound := range h.d.buckets {
+			cumulative += s.value.counts[i]
+			writeSample(b, h.d.name+"_bucket", h.d.labels, s.labelValues, "le", formatFloat(bound), float64(cumulative))
+		}
+		writeSample(b, h.d.name+"_bucket", h.d.labels, s.labelValues, "le", "+Inf", float64(s.value.count))
+		writeSample(b, h.d.name+"_sum", h.d.labels, s.labelValues, "", "", s.value.sum)
+		writeSample(b, h.d.name+"_count", h.d.labels, s.labelValues, "", "", float64(s.value.count))
+		s.value.mu.Unlock()
+	}
+}
+
+func writeSample(b *strings.Builder, name string, labels, values []string, extraLabel, extraValue string, v float64) {
+	b.WriteString(name)
+	if len(labels) > 0 || extraLabel != "" {
+		b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l + `="` + labelEscaper.Replace(values[i]) + `"`)
+		}
+		if extraLabel != "" {
+			if len(labels) > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(extraLabel + `="` + extraValue + `"`)
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(v))
+	b.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
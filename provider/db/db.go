@@ -1,12 +1,16 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"slices"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/metrics"
+	"github.com/hyper-micro/hyper/tracing"
 	"github.com/spf13/cast"
 	"xorm.io/xorm"
 	"xorm.io/xorm/log"
@@ -14,15 +18,27 @@ import (
 
 type Provider interface {
 	Into(instance ...string) *xorm.Engine
+	Ready(ctx context.Context) error
 }
 
 type dbProvider struct {
 	engines map[string]*xorm.Engine
+	health  *health.Registry
 }
 
-func NewProvider(conf config.Config) (Provider, func(), error) {
+// NewProvider connects the db.db instances. registry receives the
+// readiness check and defaults to health.Default().
+func NewProvider(conf config.Config, registry *health.Registry) (Provider, func(), error) {
+	if registry == nil {
+		registry = health.Default()
+	}
 	var engines = make(map[string]*xorm.Engine)
 
+	var queryDuration *metrics.Histogram
+	if conf.GetBoolOrDefault("db.metrics", true) {
+		queryDuration = newQueryDuration(metrics.Default())
+	}
+
 	cfg := conf.GetStringMap("db.db")
 	for k, c := range cfg {
 		m, ok := c.(map[string]interface{})
@@ -56,15 +72,24 @@ func NewProvider(conf config.Config) (Provider, func(), error) {
 			log.NewSimpleLogger3(os.Stdout, log.DEFAULT_LOG_PREFIX, log.DEFAULT_LOG_FLAG, log.LOG_WARNING),
 		)
 
-		engine.AddHook(Hook{
+		hook := Hook{
+			instance: k,
+			driver:   driver,
 			host:     cast.ToString(m["host"]),
 			port:     cast.ToInt(m["port"]),
 			database: cast.ToString(m["dbname"]),
-		})
+			duration: queryDuration,
+		}
+		if conf.GetBoolOrDefault("db.tracing", true) {
+			hook.tracer = tracing.Default()
+		}
+		engine.AddHook(hook)
 		engines[k] = engine
 	}
 
-	provider := &dbProvider{engines}
+	provider := &dbProvider{engines: engines, health: registry}
+
+	provider.health.Register("db", health.Readiness, health.CheckerFunc(provider.Ready))
 
 	return provider, provider.cleanup, nil
 }
@@ -81,7 +106,17 @@ func (p *dbProvider) Into(instance ...string) *xorm.Engine {
 	return engine
 }
 
+func (p *dbProvider) Ready(ctx context.Context) error {
+	for k, engine := range p.engines {
+		if err := engine.PingContext(ctx); err != nil {
+			return fmt.Errorf("db instance '%s' ping: %w", k, err)
+		}
+	}
+	return nil
+}
+
 func (p *dbProvider) cleanup() {
+	p.health.Unregister("db")
 	for _, engine := range p.engines {
 		_ = engine.Close()
 	}
@@ -2,36 +2,71 @@ package db
 
 import (
 	"context"
+	"strings"
 
+	"github.com/hyper-micro/hyper/metrics"
+	"github.com/hyper-micro/hyper/tracing"
 	"xorm.io/xorm/contexts"
 )
 
 type Hook struct {
+	instance string
+	driver   string
 	host     string
 	port     int
 	database string
+	duration *metrics.Histogram
+	tracer   *tracing.Tracer
 }
 
-func (Hook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
-	return c.Ctx, nil
+func newQueryDuration(r *metrics.Registry) *metrics.Histogram {
+	return r.NewHistogram("db_query_duration_seconds", "SQL statement latency, by operation.", metrics.DefBuckets, "instance", "database", "operation", "status")
+}
+
+func (h Hook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
+	if h.tracer == nil || c.Ctx == nil {
+		return c.Ctx, nil
+	}
+	ctx, span := h.tracer.Start(c.Ctx, sqlOperation(c.SQL)+" "+h.database, tracing.WithKind(tracing.SpanKindClient))
+	span.SetAttribute("db.system", h.system())
+	span.SetAttribute("db.namespace", h.database)
+	span.SetAttribute("db.operation.name", sqlOperation(c.SQL))
+	span.SetAttribute("db.query.text", c.SQL)
+	span.SetAttribute("server.address", h.host)
+	span.SetAttribute("server.port", h.port)
+	return ctx, nil
 }
 
 func (h Hook) AfterProcess(c *contexts.ContextHook) error {
-	//sp, _ := sdk.CSpan(c.Ctx, nil, nil, porter.TagMySQLClient).Start()
-	//sp.Describe(porter.Describe{
-	//	ToSvcName: porter.String(h.host),
-	//	ToSvcPort: porter.Int64(cast.ToInt64(h.port)),
-	//	ToMethod:  porter.String(h.database),
-	//})
-	//sp.Metadata(porter.MD{
-	//	"isSlow": c.ExecuteTime > time.Second,
-	//})
-	//
-	//sp.Fields(porter.MD{
-	//	"sql":         c.SQL,
-	//	"executeTime": c.ExecuteTime.Milliseconds(),
-	//	"args":        c.Args,
-	//})
-	//sp.MaybeError(c.Err).Sync()
+	if h.tracer != nil && c.Ctx != nil {
+		if span := tracing.SpanFromContext(c.Ctx); span != nil {
+			span.RecordError(c.Err)
+			span.End()
+		}
+	}
+	if h.duration != nil {
+		status := "ok"
+		if c.Err != nil {
+			status = "error"
+		}
+		h.duration.Observe(c.ExecuteTime.Seconds(), h.instance, h.database, sqlOperation(c.SQL), status)
+	}
 	return nil
 }
+
+func (h Hook) system() string {
+	if h.driver == "pg" {
+		return "postgresql"
+	}
+	return h.driver
+}
+
+// sqlOperation returns the lower-cased leading keyword of query, such as
+// "select", to keep statements out of metric labels and span names.
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	return strings.ToLower(fields[0])
+}
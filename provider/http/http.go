@@ -1,29 +1,47 @@
 package http
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"time"
 
 	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/metrics"
 	"github.com/hyper-micro/hyper/server/web"
+	"github.com/hyper-micro/hyper/tracing"
 )
 
 type Provider interface {
 	Into() *web.Server
+	Start(ctx context.Context) error
+	Ready(ctx context.Context) error
+	Listener() net.Listener
+	SetListener(ln net.Listener)
 	Run() error
 	Shutdown() error
+	ShutdownContext(ctx context.Context) error
 	Addr() string
+	Name() string
 }
 
 type httpProvider struct {
-	addr string
-	opt  web.Option
-	srv  *web.Server
-	conf config.Config
+	addr   string
+	opt    web.Option
+	srv    *web.Server
+	conf   config.Config
+	health *health.Registry
 }
 
 type ServerOption func(option *web.Option)
 
-func NewProvider(conf config.Config, serverOptions ...ServerOption) Provider {
+// NewProvider builds the server of server.http. registry receives the
+// readiness check and defaults to health.Default().
+func NewProvider(conf config.Config, registry *health.Registry, serverOptions ...ServerOption) Provider {
+	if registry == nil {
+		registry = health.Default()
+	}
 	addr := conf.GetStringOrDefault("server.http.addr", ":8080")
 	timeout := conf.GetDurationOrDefault("server.http.timeout", 30*time.Second)
 	opt := web.Option{
@@ -37,17 +55,30 @@ func NewProvider(conf config.Config, serverOptions ...ServerOption) Provider {
 		},
 	}
 
+	if conf.GetBoolOrDefault("server.http.metrics", true) {
+		opt.Metrics = metrics.Default()
+	}
+	if conf.GetBoolOrDefault("server.http.tracing", true) {
+		opt.Tracer = tracing.Default()
+	}
+
 	for _, apply := range serverOptions {
 		apply(&opt)
 	}
 
+	srv := web.New(opt)
+	srv.Use(middlewares(conf)...)
+
 	p := &httpProvider{
-		addr: addr,
-		opt:  opt,
-		srv:  web.New(opt),
-		conf: conf,
+		addr:   addr,
+		opt:    opt,
+		srv:    srv,
+		conf:   conf,
+		health: registry,
 	}
 
+	p.health.Register("http", health.Readiness, health.CheckerFunc(p.Ready))
+
 	return p
 }
 
@@ -55,14 +86,43 @@ func (p *httpProvider) Into() *web.Server {
 	return p.srv
 }
 
+func (p *httpProvider) Start(ctx context.Context) error {
+	return p.srv.Listen(ctx)
+}
+
+func (p *httpProvider) Ready(ctx context.Context) error {
+	if !p.srv.Listening() {
+		return fmt.Errorf("http: not listening on %s", p.addr)
+	}
+	return nil
+}
+
+func (p *httpProvider) Listener() net.Listener {
+	return p.srv.Listener()
+}
+
+func (p *httpProvider) SetListener(ln net.Listener) {
+	p.srv.SetListener(ln)
+}
+
 func (p *httpProvider) Run() error {
 	return p.srv.Run()
 }
 
 func (p *httpProvider) Shutdown() error {
+	p.health.Unregister("http")
 	return p.srv.Shutdown()
 }
 
+func (p *httpProvider) ShutdownContext(ctx context.Context) error {
+	p.health.Unregister("http")
+	return p.srv.ShutdownContext(ctx)
+}
+
 func (p *httpProvider) Addr() string {
 	return p.addr
 }
+
+func (p *httpProvider) Name() string {
+	return "http"
+}
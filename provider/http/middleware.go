@@ -0,0 +1,91 @@
+package http
+
+import (
+	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/server/web"
+	"github.com/hyper-micro/hyper/server/web/middleware"
+)
+
+// middlewares builds the built-in middleware enabled in conf, outermost
+// first:
+//
+//	server.http.realIp.trustedProxies       proxies trusted for X-Forwarded-For; enables RealIP
+//	server.http.realIp.headers              headers carrying the client address
+//	server.http.requestId.enabled           default true
+//	server.http.requestId.header            default X-Request-ID
+//	server.http.accessLog.enabled           default false
+//	server.http.secureHeaders.enabled       default false
+//	server.http.secureHeaders.*             frameOptions, contentTypeOptions, referrerPolicy,
+//	                                        contentSecurityPolicy, permissionsPolicy,
+//	                                        crossOriginOpenerPolicy, hstsMaxAge,
+//	                                        hstsIncludeSubdomains, hstsPreload
+//	server.http.cors.enabled                default false
+//	server.http.cors.allowOrigins           default ["*"], or none with allowCredentials
+//	server.http.cors.*                      allowMethods, allowHeaders, exposeHeaders,
+//	                                        allowCredentials, maxAge
+//	server.http.bodyLimit                   maximum request body bytes, 0 for none
+//	server.http.requestTimeout              request context timeout, 0 for none
+//	server.http.compress.enabled            default false
+//	server.http.compress.*                  level, minLength, contentTypes
+func middlewares(conf config.Config) []web.MiddlewareHandler {
+	var mws []web.MiddlewareHandler
+
+	if proxies := conf.GetStringSlice("server.http.realIp.trustedProxies"); len(proxies) > 0 {
+		mws = append(mws, middleware.RealIP(middleware.RealIPOption{
+			TrustedProxies: proxies,
+			Headers:        conf.GetStringSlice("server.http.realIp.headers"),
+		}))
+	}
+	if conf.GetBoolOrDefault("server.http.requestId.enabled", true) {
+		mws = append(mws, middleware.RequestID(middleware.RequestIDOption{
+			Header: conf.GetString("server.http.requestId.header"),
+		}))
+	}
+	if conf.GetBoolOrDefault("server.http.accessLog.enabled", false) {
+		mws = append(mws, middleware.AccessLog(middleware.AccessLogOption{}))
+	}
+	if conf.GetBoolOrDefault("server.http.secureHeaders.enabled", false) {
+		def := middleware.DefaultSecureHeadersOption
+		mws = append(mws, middleware.SecureHeaders(middleware.SecureHeadersOption{
+			ContentTypeOptions:      conf.GetStringOrDefault("server.http.secureHeaders.contentTypeOptions", def.ContentTypeOptions),
+			FrameOptions:            conf.GetStringOrDefault("server.http.secureHeaders.frameOptions", def.FrameOptions),
+			ReferrerPolicy:          conf.GetStringOrDefault("server.http.secureHeaders.referrerPolicy", def.ReferrerPolicy),
+			ContentSecurityPolicy:   conf.GetStringOrDefault("server.http.secureHeaders.contentSecurityPolicy", def.ContentSecurityPolicy),
+			PermissionsPolicy:       conf.GetStringOrDefault("server.http.secureHeaders.permissionsPolicy", def.PermissionsPolicy),
+			CrossOriginOpenerPolicy: conf.GetStringOrDefault("server.http.secureHeaders.crossOriginOpenerPolicy", def.CrossOriginOpenerPolicy),
+			HSTSMaxAge:              conf.GetDurationOrDefault("server.http.secureHeaders.hstsMaxAge", def.HSTSMaxAge),
+			HSTSIncludeSubdomains:   conf.GetBool("server.http.secureHeaders.hstsIncludeSubdomains"),
+			HSTSPreload:             conf.GetBool("server.http.secureHeaders.hstsPreload"),
+		}))
+	}
+	if conf.GetBoolOrDefault("server.http.cors.enabled", false) {
+		credentials := conf.GetBool("server.http.cors.allowCredentials")
+		// Any origin is only a safe default without credentials.
+		var defaultOrigins []string
+		if !credentials {
+			defaultOrigins = []string{"*"}
+		}
+		mws = append(mws, middleware.CORS(middleware.CORSOption{
+			AllowOrigins:     conf.GetStringSliceOrDefault("server.http.cors.allowOrigins", defaultOrigins),
+			AllowMethods:     conf.GetStringSlice("server.http.cors.allowMethods"),
+			AllowHeaders:     conf.GetStringSlice("server.http.cors.allowHeaders"),
+			ExposeHeaders:    conf.GetStringSlice("server.http.cors.exposeHeaders"),
+			AllowCredentials: credentials,
+			MaxAge:           conf.GetDuration("server.http.cors.maxAge"),
+		}))
+	}
+	if limit := conf.GetInt("server.http.bodyLimit"); limit > 0 {
+		mws = append(mws, middleware.BodyLimit(int64(limit)))
+	}
+	if timeout := conf.GetDuration("server.http.requestTimeout"); timeout > 0 {
+		mws = append(mws, middleware.Timeout(timeout))
+	}
+	if conf.GetBoolOrDefault("server.http.compress.enabled", false) {
+		mws = append(mws, middleware.Compress(middleware.CompressOption{
+			Level:        conf.GetInt("server.http.compress.level"),
+			MinLength:    conf.GetInt("server.http.compress.minLength"),
+			ContentTypes: conf.GetStringSlice("server.http.compress.contentTypes"),
+		}))
+	}
+	return mws
+}
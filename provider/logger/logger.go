@@ -24,7 +24,12 @@ func NewProvider(conf config.Config) (Provider, func(), error) {
 		Encoder:        "json",
 		Caller:         true,
 	})
-	return &loggerProvider{logger: instance}, func() {}, nil
+	prev := logger.Default()
+	logger.SetDefault(instance)
+
+	return &loggerProvider{logger: instance}, func() {
+		logger.SetDefault(prev)
+	}, nil
 }
 
 func (p *loggerProvider) Into() logger.Logger {
@@ -0,0 +1,133 @@
+package mux
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/provider/http"
+	"github.com/hyper-micro/hyper/provider/rpc"
+	"github.com/hyper-micro/hyper/provider/websocket"
+	"github.com/hyper-micro/hyper/server/mux"
+)
+
+type Provider interface {
+	Into() *mux.Server
+	Start(ctx context.Context) error
+	Ready(ctx context.Context) error
+	Listener() net.Listener
+	SetListener(ln net.Listener)
+	Run() error
+	Shutdown() error
+	ShutdownContext(ctx context.Context) error
+	Addr() string
+	Name() string
+}
+
+// Servers are the servers sharing the mux listener. Nil servers are left
+// on their own port and their protocol is refused by the mux.
+type Servers struct {
+	HTTP      http.Provider
+	RPC       rpc.Provider
+	WebSocket websocket.Provider
+}
+
+type muxProvider struct {
+	addr   string
+	srv    *mux.Server
+	health *health.Registry
+}
+
+// NewProvider serves the given servers on server.mux.addr. It must be
+// registered as an app alongside them; they keep their own Run and
+// Shutdown but accept from the mux instead of binding server.*.addr.
+// The mux only sniffs cleartext traffic, so the servers must not use TLS.
+// registry receives the readiness check and defaults to health.Default().
+func NewProvider(conf config.Config, registry *health.Registry, servers Servers) Provider {
+	if registry == nil {
+		registry = health.Default()
+	}
+	addr := conf.GetStringOrDefault("server.mux.addr", ":8080")
+	srv := mux.New(mux.Option{
+		Config: mux.Config{
+			Addr:         addr,
+			SniffTimeout: conf.GetDurationOrDefault("server.mux.sniffTimeout", 10*time.Second),
+		},
+	})
+
+	children := make(map[mux.Protocol]interface{ SetListener(net.Listener) })
+	if servers.HTTP != nil {
+		children[mux.HTTP] = servers.HTTP
+	}
+	if servers.RPC != nil {
+		children[mux.GRPC] = servers.RPC
+	}
+	if servers.WebSocket != nil {
+		children[mux.WebSocket] = servers.WebSocket
+	}
+	for _, proto := range []mux.Protocol{mux.HTTP, mux.GRPC, mux.WebSocket} {
+		if child, ok := children[proto]; ok {
+			child.SetListener(srv.ChildListener(proto))
+		} else {
+			_ = srv.ChildListener(proto).Close()
+		}
+	}
+
+	p := &muxProvider{
+		addr:   addr,
+		srv:    srv,
+		health: registry,
+	}
+
+	p.health.Register("mux", health.Readiness, health.CheckerFunc(p.Ready))
+
+	return p
+}
+
+func (p *muxProvider) Into() *mux.Server {
+	return p.srv
+}
+
+func (p *muxProvider) Start(ctx context.Context) error {
+	return p.srv.Listen(ctx)
+}
+
+func (p *muxProvider) Ready(ctx context.Context) error {
+	if !p.srv.Listening() {
+		return fmt.Errorf("mux: not listening on %s", p.addr)
+	}
+	return nil
+}
+
+func (p *muxProvider) Listener() net.Listener {
+	return p.srv.Listener()
+}
+
+func (p *muxProvider) SetListener(ln net.Listener) {
+	p.srv.SetListener(ln)
+}
+
+func (p *muxProvider) Run() error {
+	return p.srv.Run()
+}
+
+func (p *muxProvider) Shutdown() error {
+	p.health.Unregister("mux")
+	return p.srv.Shutdown()
+}
+
+func (p *muxProvider) ShutdownContext(ctx context.Context) error {
+	p.health.Unregister("mux")
+	return p.srv.ShutdownContext(ctx)
+}
+
+func (p *muxProvider) Addr() string {
+	return p.addr
+}
+
+func (p *muxProvider) Name() string {
+	return "mux"
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"fmt"
+
+	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/provider/redis"
+	"github.com/hyper-micro/hyper/ratelimit"
+)
+
+type Provider interface {
+	// Into returns the store shared by the application's limiters.
+	Into() ratelimit.Store
+	// New returns a limiter named name, using the shared store.
+	New(name string, rate ratelimit.Rate, alg ratelimit.Algorithm) *ratelimit.Limiter
+}
+
+type ratelimitProvider struct {
+	store ratelimit.Store
+}
+
+// NewProvider builds the store configured by server.ratelimit.store:
+// "memory", the default, or "redis", which uses the client of the
+// server.ratelimit.redis instance of rdb and prefixes keys with
+// server.ratelimit.prefix. rdb may be nil for the memory store.
+func NewProvider(conf config.Config, rdb redis.Provider) (Provider, error) {
+	var store ratelimit.Store
+	switch kind := conf.GetStringOrDefault("server.ratelimit.store", "memory"); kind {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "redis":
+		if rdb == nil {
+			return nil, fmt.Errorf("ratelimit: redis store requires the redis provider")
+		}
+		client := rdb.Into(conf.GetStringOrDefault("server.ratelimit.redis", "default"))
+		store = ratelimit.NewRedisStore(client, conf.GetString("server.ratelimit.prefix"))
+	default:
+		return nil, fmt.Errorf("ratelimit: unknown store %q", kind)
+	}
+	return &ratelimitProvider{store: store}, nil
+}
+
+func (p *ratelimitProvider) Into() ratelimit.Store {
+	return p.store
+}
+
+func (p *ratelimitProvider) New(name string, rate ratelimit.Rate, alg ratelimit.Algorithm) *ratelimit.Limiter {
+	return ratelimit.New(ratelimit.Option{
+		Name:      name,
+		Rate:      rate,
+		Algorithm: alg,
+		Store:     p.store,
+	})
+}
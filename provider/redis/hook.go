@@ -2,30 +2,85 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"net"
+	"time"
 
+	"github.com/hyper-micro/hyper/metrics"
+	"github.com/hyper-micro/hyper/tracing"
 	"github.com/redis/go-redis/v9"
 )
 
 type Hook struct {
-	host string
+	instance string
+	host     string
+	port     int
+	duration *metrics.Histogram
+	tracer   *tracing.Tracer
+}
+
+func newCommandDuration(r *metrics.Registry) *metrics.Histogram {
+	return r.NewHistogram("redis_command_duration_seconds", "Redis command latency, by command.", metrics.DefBuckets, "instance", "command", "status")
 }
 
 func (h Hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.startSpan(ctx, cmd.Name())
+		start := time.Now()
 		err := next(ctx, cmd)
+		h.observe(cmd.Name(), start, err)
+		h.endSpan(span, err)
 		return err
 	}
 }
 
+func (h Hook) observe(command string, start time.Time, err error) {
+	if h.duration == nil {
+		return
+	}
+	status := "ok"
+	if err != nil && !errors.Is(err, redis.Nil) {
+		status = "error"
+	}
+	h.duration.Observe(time.Since(start).Seconds(), h.instance, command, status)
+}
+
+func (h Hook) startSpan(ctx context.Context, command string) (context.Context, *tracing.Span) {
+	if h.tracer == nil {
+		return ctx, nil
+	}
+	ctx, span := h.tracer.Start(ctx, "redis "+command, tracing.WithKind(tracing.SpanKindClient))
+	span.SetAttribute("db.system", "redis")
+	span.SetAttribute("db.operation.name", command)
+	span.SetAttribute("server.address", h.host)
+	span.SetAttribute("server.port", h.port)
+	return ctx, span
+}
+
+func (h Hook) endSpan(span *tracing.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
 func (Hook) DialHook(next redis.DialHook) redis.DialHook {
 	return func(ctx context.Context, network, addr string) (net.Conn, error) {
 		return next(ctx, network, addr)
 	}
 }
 
-func (Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+func (h Hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return func(ctx context.Context, cmds []redis.Cmder) error {
-		return next(ctx, cmds)
+		ctx, span := h.startSpan(ctx, "pipeline")
+		span.SetAttribute("db.operation.batch.size", len(cmds))
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe("pipeline", start, err)
+		h.endSpan(span, err)
+		return err
 	}
 }
@@ -7,20 +7,35 @@ import (
 	"runtime"
 
 	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/metrics"
+	"github.com/hyper-micro/hyper/tracing"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/cast"
 )
 
 type Provider interface {
 	Into(instance ...string) *redis.Client
+	Ready(ctx context.Context) error
 }
 
 type redisProvider struct {
 	clients map[string]*redis.Client
+	health  *health.Registry
 }
 
-func NewProvider(conf config.Config) (Provider, func(), error) {
+// NewProvider connects the db.redis instances. registry receives the
+// readiness check and defaults to health.Default().
+func NewProvider(conf config.Config, registry *health.Registry) (Provider, func(), error) {
+	if registry == nil {
+		registry = health.Default()
+	}
 	var clients = make(map[string]*redis.Client)
+
+	var commandDuration *metrics.Histogram
+	if conf.GetBoolOrDefault("db.metrics", true) {
+		commandDuration = newCommandDuration(metrics.Default())
+	}
 	cfg := conf.GetStringMap("db.redis")
 	for k, c := range cfg {
 		m, ok := c.(map[string]interface{})
@@ -69,12 +84,23 @@ func NewProvider(conf config.Config) (Provider, func(), error) {
 			MaxRetries:            cast.ToInt(m["maxRetries"]),
 		})
 
-		rdb.AddHook(Hook{host})
+		hook := Hook{
+			instance: k,
+			host:     host,
+			port:     port,
+			duration: commandDuration,
+		}
+		if conf.GetBoolOrDefault("db.tracing", true) {
+			hook.tracer = tracing.Default()
+		}
+		rdb.AddHook(hook)
 
 		clients[k] = rdb
 	}
 
-	provider := &redisProvider{clients}
+	provider := &redisProvider{clients: clients, health: registry}
+
+	provider.health.Register("redis", health.Readiness, health.CheckerFunc(provider.Ready))
 
 	return provider, provider.cleanup, nil
 }
@@ -91,7 +117,17 @@ func (p *redisProvider) Into(instance ...string) *redis.Client {
 	return client
 }
 
+func (p *redisProvider) Ready(ctx context.Context) error {
+	for k, client := range p.clients {
+		if err := client.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("redis instance '%s' ping: %w", k, err)
+		}
+	}
+	return nil
+}
+
 func (p *redisProvider) cleanup() {
+	p.health.Unregister("redis")
 	for _, client := range p.clients {
 		_ = client.Close()
 	}
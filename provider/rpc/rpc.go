@@ -1,29 +1,49 @@
 package rpc
 
 import (
+	"context"
+	"fmt"
 	"math"
+	"net"
+	"time"
 
 	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/metrics"
 	"github.com/hyper-micro/hyper/server/rpc"
+	"github.com/hyper-micro/hyper/tracing"
 )
 
 type Provider interface {
 	Into() *rpc.Server
+	Start(ctx context.Context) error
+	Ready(ctx context.Context) error
+	Listener() net.Listener
+	SetListener(ln net.Listener)
 	Run() error
 	Shutdown() error
+	ShutdownContext(ctx context.Context) error
 	Addr() string
+	Name() string
 }
 
 type rpcProvider struct {
-	addr string
-	opt  rpc.Option
-	srv  *rpc.Server
-	conf config.Config
+	addr   string
+	opt    rpc.Option
+	srv    *rpc.Server
+	conf   config.Config
+	health *health.Registry
 }
 
 type ServerOption func(option *rpc.Option)
 
-func NewProvider(conf config.Config, serverOptions ...ServerOption) Provider {
+// NewProvider builds the server of server.rpc. registry receives the
+// readiness check, is reported by the grpc.health.v1 service and defaults
+// to health.Default().
+func NewProvider(conf config.Config, registry *health.Registry, serverOptions ...ServerOption) Provider {
+	if registry == nil {
+		registry = health.Default()
+	}
 	addr := conf.GetStringOrDefault("server.rpc.addr", "0.0.0.0:18110")
 	opt := rpc.Option{
 		Config: rpc.Config{
@@ -37,17 +57,34 @@ func NewProvider(conf config.Config, serverOptions ...ServerOption) Provider {
 		ServiceOpts: nil,
 	}
 
+	if conf.GetBoolOrDefault("server.rpc.health", true) {
+		opt.HealthInterval = conf.GetDurationOrDefault("server.rpc.healthInterval", 5*time.Second)
+		opt.HealthCheck = func(ctx context.Context) error {
+			return registry.Check(ctx, health.Readiness).Err()
+		}
+	}
+
+	if conf.GetBoolOrDefault("server.rpc.metrics", true) {
+		opt.Metrics = metrics.Default()
+	}
+	if conf.GetBoolOrDefault("server.rpc.tracing", true) {
+		opt.Tracer = tracing.Default()
+	}
+
 	for _, apply := range serverOptions {
 		apply(&opt)
 	}
 
 	p := &rpcProvider{
-		addr: addr,
-		opt:  opt,
-		srv:  rpc.New(opt),
-		conf: conf,
+		addr:   addr,
+		opt:    opt,
+		srv:    rpc.New(opt),
+		conf:   conf,
+		health: registry,
 	}
 
+	p.health.Register("rpc", health.Readiness, health.CheckerFunc(p.Ready))
+
 	return p
 }
 
@@ -55,14 +92,43 @@ func (p *rpcProvider) Into() *rpc.Server {
 	return p.srv
 }
 
+func (p *rpcProvider) Start(ctx context.Context) error {
+	return p.srv.Listen(ctx)
+}
+
+func (p *rpcProvider) Ready(ctx context.Context) error {
+	if !p.srv.Listening() {
+		return fmt.Errorf("rpc: not listening on %s", p.addr)
+	}
+	return nil
+}
+
+func (p *rpcProvider) Listener() net.Listener {
+	return p.srv.Listener()
+}
+
+func (p *rpcProvider) SetListener(ln net.Listener) {
+	p.srv.SetListener(ln)
+}
+
 func (p *rpcProvider) Run() error {
 	return p.srv.Run()
 }
 
 func (p *rpcProvider) Shutdown() error {
+	p.health.Unregister("rpc")
 	return p.srv.Shutdown()
 }
 
+func (p *rpcProvider) ShutdownContext(ctx context.Context) error {
+	p.health.Unregister("rpc")
+	return p.srv.ShutdownContext(ctx)
+}
+
 func (p *rpcProvider) Addr() string {
 	return p.addr
 }
+
+func (p *rpcProvider) Name() string {
+	return "rpc"
+}
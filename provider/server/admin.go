@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/internal/json"
+	"github.com/hyper-micro/hyper/metrics"
+	"github.com/hyper-micro/hyper/server/rpc"
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+// adminApp serves the health and metrics endpoints on the optional server.admin.addr
+// listener, away from the ports used for regular traffic. When
+// server.admin.debug is true it also serves introspection under /debug.
+type adminApp struct {
+	addr string
+	srv  *web.Server
+}
+
+// defaultMaskKeys are the key fragments whose values /debug/config hides.
+var defaultMaskKeys = []string{"password", "passwd", "secret", "token", "credential", "dsn", "apikey", "privatekey", "accesskey"}
+
+const maskedValue = "******"
+
+func newAdminApp(s *serverProvider, addr string, apps []App) *adminApp {
+	srv := web.New(web.Option{
+		Config: web.Config{
+			Addr: addr,
+		},
+	})
+	srv.Get("/healthz", healthHandler(s.health, health.All))
+	srv.Get("/readyz", healthHandler(s.health, health.Readiness))
+	srv.Get("/livez", healthHandler(s.health, health.Liveness))
+	srv.Get(s.conf.GetStringOrDefault("server.admin.metricsPath", "/metrics"), httpHandler(metrics.Handler().ServeHTTP))
+
+	if s.conf.GetBoolOrDefault("server.admin.debug", false) {
+		srv.Get("/debug/pprof/", httpHandler(pprof.Index))
+		srv.Get("/debug/pprof/cmdline", httpHandler(pprof.Cmdline))
+		srv.Get("/debug/pprof/profile", httpHandler(pprof.Profile))
+		srv.Get("/debug/pprof/symbol", httpHandler(pprof.Symbol))
+		srv.Post("/debug/pprof/symbol", httpHandler(pprof.Symbol))
+		srv.Get("/debug/pprof/trace", httpHandler(pprof.Trace))
+		srv.Get("/debug/pprof/{name}", httpHandler(pprof.Index))
+		srv.Get("/debug/vars", httpHandler(expvar.Handler().ServeHTTP))
+
+		maskKeys := append(append([]string(nil), defaultMaskKeys...), s.conf.GetStringSlice("server.admin.maskKeys")...)
+		srv.Get("/debug/config", func(ctx web.Ctx) {
+			writeJSON(ctx, http.StatusOK, maskSettings(s.conf.AllSettings(), maskKeys))
+		})
+		srv.Get("/debug/buildinfo", func(ctx web.Ctx) {
+			writeJSON(ctx, http.StatusOK, s.buildInfo())
+		})
+		srv.Get("/debug/routes", func(ctx web.Ctx) {
+			writeJSON(ctx, http.StatusOK, appRoutes(apps))
+		})
+		srv.Get("/debug/services", func(ctx web.Ctx) {
+			writeJSON(ctx, http.StatusOK, appServices(apps))
+		})
+	}
+
+	return &adminApp{
+		addr: addr,
+		srv:  srv,
+	}
+}
+
+func httpHandler(f http.HandlerFunc) web.Handler {
+	return func(ctx web.Ctx) {
+		f(ctx.Writer(), ctx.Request())
+	}
+}
+
+func writeJSON(ctx web.Ctx, code int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		_ = ctx.ResponseWithStatus(http.StatusInternalServerError, []byte(err.Error()))
+		return
+	}
+	ctx.Header("Content-Type", "application/json; charset=utf-8")
+	_ = ctx.ResponseWithStatus(code, b)
+}
+
+type buildInfo struct {
+	AppName     string `json:"appName"`
+	Version     string `json:"version"`
+	BuildCommit string `json:"buildCommit"`
+	BuildDate   string `json:"buildDate"`
+	GoVersion   string `json:"goVersion"`
+	Path        string `json:"path,omitempty"`
+	Pid         int    `json:"pid"`
+}
+
+func (s *serverProvider) buildInfo() buildInfo {
+	info := buildInfo{
+		AppName:     s.opt.AppName,
+		Version:     s.opt.Version,
+		BuildCommit: s.opt.BuildCommit,
+		BuildDate:   s.opt.BuildDate,
+		GoVersion:   runtime.Version(),
+		Pid:         os.Getpid(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Path = bi.Main.Path
+	}
+	return info
+}
+
+func maskSettings(settings map[string]interface{}, maskKeys []string) map[string]interface{} {
+	for k, v := range settings {
+		if isMaskKey(k, maskKeys) {
+			if _, isMap := v.(map[string]interface{}); !isMap {
+				settings[k] = maskedValue
+				continue
+			}
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			maskSettings(val, maskKeys)
+		case []interface{}:
+			for _, item := range val {
+				if m, ok := item.(map[string]interface{}); ok {
+					maskSettings(m, maskKeys)
+				}
+			}
+		}
+	}
+	return settings
+}
+
+func isMaskKey(key string, maskKeys []string) bool {
+	key = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, mk := range maskKeys {
+		if strings.Contains(key, strings.ToLower(mk)) {
+			return true
+		}
+	}
+	return false
+}
+
+func appRoutes(apps []App) map[string][]web.RouteInfo {
+	routes := make(map[string][]web.RouteInfo)
+	for _, app := range apps {
+		if p, ok := unwrapApp[interface{ Into() *web.Server }](app); ok {
+			routes[app.Name()] = p.Into().Routes()
+		}
+	}
+	return routes
+}
+
+type serviceInfo struct {
+	Name    string   `json:"name"`
+	Methods []string `json:"methods"`
+}
+
+func appServices(apps []App) map[string][]serviceInfo {
+	services := make(map[string][]serviceInfo)
+	for _, app := range apps {
+		p, ok := unwrapApp[interface{ Into() *rpc.Server }](app)
+		if !ok {
+			continue
+		}
+		list := []serviceInfo{}
+		for name, info := range p.Into().Services() {
+			si := serviceInfo{Name: name}
+			for _, m := range info.Methods {
+				si.Methods = append(si.Methods, m.Name)
+			}
+			list = append(list, si)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		services[app.Name()] = list
+	}
+	return services
+}
+
+func healthHandler(registry *health.Registry, kind health.Kind) web.Handler {
+	return func(ctx web.Ctx) {
+		report := registry.Check(ctx, kind)
+		code := http.StatusOK
+		if !report.OK() {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(ctx, code, report)
+	}
+}
+
+func (a *adminApp) Start(ctx context.Context) error {
+	return a.srv.Listen(ctx)
+}
+
+func (a *adminApp) Ready(ctx context.Context) error {
+	if !a.srv.Listening() {
+		return fmt.Errorf("admin: not listening on %s", a.addr)
+	}
+	return nil
+}
+
+func (a *adminApp) Listener() net.Listener {
+	return a.srv.Listener()
+}
+
+func (a *adminApp) SetListener(ln net.Listener) {
+	a.srv.SetListener(ln)
+}
+
+func (a *adminApp) Run() error {
+	return a.srv.Run()
+}
+
+func (a *adminApp) Shutdown() error {
+	return a.srv.Shutdown()
+}
+
+func (a *adminApp) ShutdownContext(ctx context.Context) error {
+	return a.srv.ShutdownContext(ctx)
+}
+
+func (a *adminApp) Addr() string {
+	return a.addr
+}
+
+func (a *adminApp) Name() string {
+	return "admin"
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyper-micro/hyper/internal/json"
+	"github.com/hyper-micro/hyper/server/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type webApp struct {
+	testApp
+	srv *web.Server
+}
+
+func (a *webApp) Into() *web.Server {
+	return a.srv
+}
+
+func TestAdmin_Debug(t *testing.T) {
+	s := newTestProvider(t, Option{AppName: "svc", Version: "1.2.3"})
+	s.conf.Set("db.default.password", "hunter2")
+	s.conf.Set("db.default.host", "localhost")
+	s.conf.Set("server.admin.maskKeys", []string{"host"})
+
+	srv := web.New(web.Option{})
+	srv.Get("/users/{id}", func(web.Ctx) {})
+	apps := []App{&webApp{testApp: testApp{"http"}, srv: srv}}
+
+	// Introspection is opt-in.
+	rec := httptest.NewRecorder()
+	newAdminApp(s, "127.0.0.1:0", apps).srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/config", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	s.conf.Set("server.admin.debug", true)
+	admin := newAdminApp(s, "127.0.0.1:0", apps)
+
+	get := func(path string, v any) {
+		rec := httptest.NewRecorder()
+		admin.srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		if v != nil {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+		}
+	}
+
+	var conf map[string]map[string]map[string]any
+	get("/debug/config", &conf)
+	assert.Equal(t, maskedValue, conf["db"]["default"]["password"])
+	assert.Equal(t, maskedValue, conf["db"]["default"]["host"])
+
+	var info buildInfo
+	get("/debug/buildinfo", &info)
+	assert.Equal(t, "svc", info.AppName)
+	assert.Equal(t, "1.2.3", info.Version)
+
+	var routes map[string][]web.RouteInfo
+	get("/debug/routes", &routes)
+	assert.Equal(t, []web.RouteInfo{{Methods: []string{http.MethodGet}, Path: "/users/{id}"}}, routes["http"])
+
+	get("/metrics", nil)
+	get("/debug/pprof/", nil)
+	get("/debug/vars", nil)
+}
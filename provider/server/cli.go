@@ -0,0 +1,356 @@
+package server
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hyper-micro/hyper/config"
+	"github.com/spf13/cast"
+)
+
+// Flag declares a command line flag. Its value is taken from the command
+// line, then from the first non-empty EnvVars entry, then from Value.
+type Flag struct {
+	Name    string
+	Aliases []string
+	Usage   string
+	Value   string
+	EnvVars []string
+	Bool    bool
+	// ConfigKey, when set, makes an explicitly given flag override the
+	// config value at this key.
+	ConfigKey string
+}
+
+type CommandAction func(ctx context.Context, conf config.Config, flags *Flags) error
+
+// Command is a subcommand such as `svc migrate`. When one is given on the
+// command line, Run executes its Action instead of starting the apps.
+type Command struct {
+	Name   string
+	Usage  string
+	Flags  []Flag
+	Action CommandAction
+}
+
+type flagValue struct {
+	flag  *Flag
+	value string
+	set   bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	if v.flag.Bool {
+		if _, err := cast.ToBoolE(s); err != nil {
+			return err
+		}
+	}
+	v.value = s
+	v.set = true
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.flag.Bool
+}
+
+type setValue struct {
+	kv [][2]string
+}
+
+func (v *setValue) String() string {
+	return ""
+}
+
+func (v *setValue) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	v.kv = append(v.kv, [2]string{key, value})
+	return nil
+}
+
+// Flags holds the parsed values of declared flags and the remaining
+// positional arguments.
+type Flags struct {
+	values map[string]*flagValue
+	args   []string
+}
+
+func (f *Flags) lookup(name string) *flagValue {
+	if f == nil {
+		return nil
+	}
+	return f.values[name]
+}
+
+func (f *Flags) String(name string) string {
+	return f.lookup(name).String()
+}
+
+func (f *Flags) Bool(name string) bool {
+	return cast.ToBool(f.String(name))
+}
+
+func (f *Flags) Int(name string) int {
+	return cast.ToInt(f.String(name))
+}
+
+func (f *Flags) Duration(name string) time.Duration {
+	return cast.ToDuration(f.String(name))
+}
+
+// IsSet reports whether the flag was given on the command line or through
+// one of its environment variables.
+func (f *Flags) IsSet(name string) bool {
+	v := f.lookup(name)
+	return v != nil && v.set
+}
+
+func (f *Flags) Args() []string {
+	if f == nil {
+		return nil
+	}
+	return f.args
+}
+
+func newFlagSet(name string, flags []Flag, lookupEnv func(string) (string, bool)) (*flag.FlagSet, *Flags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {}
+	fs.SetOutput(io.Discard)
+
+	parsed := &Flags{values: make(map[string]*flagValue)}
+	for i := range flags {
+		f := &flags[i]
+		v := &flagValue{flag: f, value: f.Value}
+		for _, env := range f.EnvVars {
+			if val, ok := lookupEnv(env); ok && val != "" {
+				v.value = val
+				v.set = true
+				break
+			}
+		}
+		for _, name := range append([]string{f.Name}, f.Aliases...) {
+			fs.Var(v, name, f.Usage)
+			parsed.values[name] = v
+		}
+	}
+	return fs, parsed
+}
+
+var helpFlag = Flag{Name: "help", Aliases: []string{"h"}, Usage: "show help", Bool: true}
+
+func (s *serverProvider) builtinFlags() []Flag {
+	return []Flag{
+		{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Usage:   "set configure file path",
+			Value:   s.opt.ConfigDefault,
+			EnvVars: s.envVars("config"),
+		},
+		helpFlag,
+		{Name: "version", Aliases: []string{"v"}, Usage: "show version", Bool: true},
+	}
+}
+
+// envVars returns the variable bound to a flag through Option.EnvPrefix,
+// e.g. APP_CONFIG for the config flag when the prefix is "APP".
+func (s *serverProvider) envVars(name string) []string {
+	if s.opt.EnvPrefix == "" {
+		return nil
+	}
+	env := strings.ToUpper(s.opt.EnvPrefix + "_" + strings.ReplaceAll(name, "-", "_"))
+	return []string{env}
+}
+
+func (s *serverProvider) withEnvPrefix(flags []Flag) []Flag {
+	out := make([]Flag, len(flags))
+	for i, f := range flags {
+		if len(f.EnvVars) == 0 {
+			f.EnvVars = s.envVars(f.Name)
+		}
+		out[i] = f
+	}
+	return out
+}
+
+func (s *serverProvider) parseArgs() error {
+	args := s.opt.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+	lookupEnv := s.opt.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	globalFlags := append(s.builtinFlags(), s.withEnvPrefix(s.opt.Flags)...)
+	fs, flags := newFlagSet(s.opt.AppName, globalFlags, lookupEnv)
+	sets := new(setValue)
+	fs.Var(sets, "set", "override a config key, e.g. --set server.http.addr=:9000")
+	if err := fs.Parse(args); err != nil {
+		return s.usageError(err, nil)
+	}
+	flags.args = fs.Args()
+	s.flags = flags
+	s.sets = sets.kv
+
+	if flags.Bool("help") || flags.Bool("version") {
+		return nil
+	}
+
+	if len(flags.args) > 0 {
+		cmd := s.command(flags.args[0])
+		if cmd == nil {
+			return s.usageError(fmt.Errorf("unknown command %q", flags.args[0]), nil)
+		}
+		if cmd.Action == nil {
+			return fmt.Errorf("command %q has no action", cmd.Name)
+		}
+		cmdFlags := append(s.withEnvPrefix(cmd.Flags), helpFlag)
+		cfs, parsedCmd := newFlagSet(cmd.Name, cmdFlags, lookupEnv)
+		if err := cfs.Parse(flags.args[1:]); err != nil {
+			return s.usageError(err, cmd)
+		}
+		parsedCmd.args = cfs.Args()
+		s.cmd = cmd
+		s.cmdFlags = parsedCmd
+	}
+	return nil
+}
+
+func (s *serverProvider) command(name string) *Command {
+	for i := range s.opt.Commands {
+		if s.opt.Commands[i].Name == name {
+			return &s.opt.Commands[i]
+		}
+	}
+	return nil
+}
+
+// applyConfigOverrides applies --set values and flags bound to config keys,
+// in that order, so a dedicated flag wins over a generic --set.
+func (s *serverProvider) applyConfigOverrides() {
+	for _, kv := range s.sets {
+		s.conf.Set(kv[0], kv[1])
+	}
+	apply := func(flags []Flag, parsed *Flags) {
+		for _, f := range flags {
+			if f.ConfigKey != "" && parsed.IsSet(f.Name) {
+				s.conf.Set(f.ConfigKey, parsed.String(f.Name))
+			}
+		}
+	}
+	apply(s.opt.Flags, s.flags)
+	if s.cmd != nil {
+		apply(s.cmd.Flags, s.cmdFlags)
+	}
+}
+
+func (s *serverProvider) helpRequested() bool {
+	return s.flags.Bool("help") || s.cmdFlags.Bool("help")
+}
+
+func (s *serverProvider) usageError(err error, cmd *Command) error {
+	name := s.opt.AppName
+	if cmd != nil {
+		name += " " + cmd.Name
+	}
+	return fmt.Errorf("%w, run '%s --help' for usage", err, name)
+}
+
+func (s *serverProvider) output() io.Writer {
+	if s.opt.Output != nil {
+		return s.opt.Output
+	}
+	return os.Stdout
+}
+
+func (s *serverProvider) printVersion() {
+	fmt.Fprintf(s.output(), "Version %s, build %s, %s\n", s.opt.Version, s.opt.BuildCommit, s.opt.BuildDate)
+}
+
+func (s *serverProvider) printUsage() {
+	w := s.output()
+	if s.cmd != nil {
+		fmt.Fprintf(w, "\nUSAGE:\n   %s %s [options] [arguments...]\n\n", s.opt.AppName, s.cmd.Name)
+		if s.cmd.Usage != "" {
+			fmt.Fprintf(w, "%s\n\n", s.cmd.Usage)
+		}
+		fmt.Fprintf(w, "OPTIONS:\n")
+		writeFlagUsage(w, append(s.withEnvPrefix(s.cmd.Flags), helpFlag))
+		fmt.Fprintln(w)
+		return
+	}
+
+	fmt.Fprintf(w, "\nUSAGE:\n   %s [options]", s.opt.AppName)
+	if len(s.opt.Commands) > 0 {
+		fmt.Fprintf(w, " [command [command options]]")
+	}
+	fmt.Fprintf(w, "\n\n%s\n\n", s.opt.AppDesc)
+
+	if len(s.opt.Commands) > 0 {
+		fmt.Fprintf(w, "COMMANDS:\n")
+		width := 0
+		for _, cmd := range s.opt.Commands {
+			width = max(width, len(cmd.Name))
+		}
+		for _, cmd := range s.opt.Commands {
+			fmt.Fprintf(w, "   %-*s  %s\n", width, cmd.Name, cmd.Usage)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "OPTIONS:\n")
+	flags := append(s.builtinFlags(), s.withEnvPrefix(s.opt.Flags)...)
+	flags = append(flags, Flag{Name: "set", Usage: "override a config key, may be repeated", Value: "key=value"})
+	writeFlagUsage(w, flags)
+	fmt.Fprintln(w)
+}
+
+func writeFlagUsage(w io.Writer, flags []Flag) {
+	names := make([]string, len(flags))
+	width := 0
+	for i, f := range flags {
+		var parts []string
+		for _, name := range append([]string{f.Name}, f.Aliases...) {
+			prefix := "--"
+			if len(name) == 1 {
+				prefix = "-"
+			}
+			if f.Bool {
+				parts = append(parts, prefix+name)
+			} else {
+				parts = append(parts, prefix+name+" value")
+			}
+		}
+		names[i] = strings.Join(parts, ", ")
+		width = max(width, len(names[i]))
+	}
+	for i, f := range flags {
+		usage := f.Usage
+		if f.Bool {
+			usage += " (default: false)"
+		} else if f.Value != "" {
+			usage += fmt.Sprintf(" (default: %q)", f.Value)
+		}
+		if len(f.EnvVars) > 0 {
+			usage += fmt.Sprintf(" [$%s]", strings.Join(f.EnvVars, ", $"))
+		}
+		fmt.Fprintf(w, "   %-*s  %s\n", width, names[i], usage)
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCLIProvider(t *testing.T, opt Option) (*serverProvider, error) {
+	opt.AppName = "svc"
+	opt.ConfigPathType = config.PathTypePath
+	opt.ConfigDefault = t.TempDir()
+	opt.Logger = loggertest.New()
+	opt.Health = health.NewRegistry()
+	if opt.LookupEnv == nil {
+		opt.LookupEnv = func(string) (string, bool) { return "", false }
+	}
+	p, _, err := NewProvider(opt)
+	if err != nil {
+		return nil, err
+	}
+	return p.(*serverProvider), nil
+}
+
+func TestCLI_HelpAndVersion(t *testing.T) {
+	var out bytes.Buffer
+	p, err := newCLIProvider(t, Option{
+		Args:     []string{"--help"},
+		Output:   &out,
+		Flags:    []Flag{{Name: "workers", Usage: "number of workers", Value: "4"}},
+		Commands: []Command{{Name: "migrate", Usage: "run database migrations", Action: nopAction}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.Run())
+	assert.Contains(t, out.String(), "--workers value")
+	assert.Contains(t, out.String(), "migrate  run database migrations")
+
+	out.Reset()
+	p, err = newCLIProvider(t, Option{Args: []string{"-v"}, Version: "1.0.0", Output: &out})
+	require.NoError(t, err)
+	require.NoError(t, p.Run())
+	assert.Contains(t, out.String(), "Version 1.0.0")
+}
+
+func TestCLI_ConfigOverrides(t *testing.T) {
+	p, err := newCLIProvider(t, Option{
+		Args: []string{
+			"--set", "server.http.addr=:9000",
+			"--set", "server.rpc.addr=:9001",
+			"--rpc-addr", ":9002",
+		},
+		EnvPrefix: "svc",
+		LookupEnv: func(key string) (string, bool) {
+			if key == "SVC_WORKERS" {
+				return "8", true
+			}
+			return "", false
+		},
+		Flags: []Flag{
+			{Name: "workers", Value: "4"},
+			{Name: "rpc-addr", ConfigKey: "server.rpc.addr"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9000", p.conf.GetString("server.http.addr"))
+	assert.Equal(t, ":9002", p.conf.GetString("server.rpc.addr"))
+	assert.Equal(t, 8, p.Flags().Int("workers"))
+	assert.True(t, p.Flags().IsSet("workers"))
+}
+
+func TestCLI_Command(t *testing.T) {
+	var got []string
+	p, err := newCLIProvider(t, Option{
+		Args: []string{"migrate", "--steps", "2", "up"},
+		Commands: []Command{{
+			Name:  "migrate",
+			Flags: []Flag{{Name: "steps", Value: "1"}},
+			Action: func(ctx context.Context, conf config.Config, flags *Flags) error {
+				got = append(got, flags.String("steps"))
+				got = append(got, flags.Args()...)
+				return nil
+			},
+		}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, p.Run())
+	assert.Equal(t, []string{"2", "up"}, got)
+}
+
+func TestCLI_Errors(t *testing.T) {
+	_, err := newCLIProvider(t, Option{Args: []string{"--unknown"}})
+	assert.ErrorContains(t, err, "run 'svc --help' for usage")
+
+	_, err = newCLIProvider(t, Option{Args: []string{"serve"}})
+	assert.ErrorContains(t, err, `unknown command "serve"`)
+
+	_, err = newCLIProvider(t, Option{Args: []string{"--set", "novalue"}})
+	assert.ErrorContains(t, err, "expected key=value")
+}
+
+func nopAction(context.Context, config.Config, *Flags) error {
+	return nil
+}
+
+func TestNewProvider_Health(t *testing.T) {
+	p, err := newCLIProvider(t, Option{Args: []string{}})
+	require.NoError(t, err)
+	assert.NotSame(t, health.Default(), p.Health())
+
+	// Checks registered with the server's registry gate the apps.
+	p.Health().Register("db", health.Readiness, health.CheckerFunc(func(context.Context) error { return nil }))
+	assert.True(t, p.health.Has("db"))
+	assert.False(t, health.Default().Has("db"))
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultStartupTimeout = 30 * time.Second
+	readyPollInterval     = 100 * time.Millisecond
+)
+
+// Starter is implemented by apps that can bind their listener before serving.
+// Start returns once the app is listening; Run then serves on that listener.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// ReadyChecker is implemented by apps that report when they can accept traffic.
+type ReadyChecker interface {
+	Ready(ctx context.Context) error
+}
+
+// Dependent is implemented by apps that must start after the named apps or
+// readiness checks in the health registry, such as "db" or "redis".
+type Dependent interface {
+	DependsOn() []string
+}
+
+type ReadyFunc func(ctx context.Context) error
+
+type dependentApp struct {
+	App
+	deps []string
+}
+
+// DependsOn wraps app so that it is started only after every named app or
+// ready check is ready.
+func DependsOn(app App, names ...string) App {
+	return &dependentApp{App: app, deps: names}
+}
+
+func (a *dependentApp) DependsOn() []string {
+	var deps []string
+	if d, ok := a.App.(Dependent); ok {
+		deps = append(deps, d.DependsOn()...)
+	}
+	return append(deps, a.deps...)
+}
+
+func (a *dependentApp) Unwrap() App {
+	return a.App
+}
+
+func unwrapApp[T any](app App) (T, bool) {
+	for {
+		if t, ok := app.(T); ok {
+			return t, true
+		}
+		u, ok := app.(interface{ Unwrap() App })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		app = u.Unwrap()
+	}
+}
+
+func appDependsOn(app App) []string {
+	if d, ok := unwrapApp[Dependent](app); ok {
+		return d.DependsOn()
+	}
+	return nil
+}
+
+// sortApps orders apps so that every app comes after its dependencies,
+// keeping registration order otherwise.
+func sortApps(apps []App, known func(name string) bool) ([]App, error) {
+	byName := make(map[string]App, len(apps))
+	for _, app := range apps {
+		if _, ok := byName[app.Name()]; ok {
+			return nil, fmt.Errorf("server: duplicate app name %q", app.Name())
+		}
+		byName[app.Name()] = app
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	var (
+		state  = make(map[string]int, len(apps))
+		sorted = make([]App, 0, len(apps))
+		path   []string
+		visit  func(app App) error
+	)
+	visit = func(app App) error {
+		name := app.Name()
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("server: dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range appDependsOn(app) {
+			if depApp, ok := byName[dep]; ok {
+				if err := visit(depApp); err != nil {
+					return err
+				}
+				continue
+			}
+			if !known(dep) {
+				return fmt.Errorf("server: %s depends on unknown %q", name, dep)
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		sorted = append(sorted, app)
+		return nil
+	}
+
+	for _, app := range apps {
+		if err := visit(app); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+func waitReady(ctx context.Context, check ReadyFunc) error {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ctx.Err(), err)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testApp struct {
+	name string
+}
+
+func (a *testApp) Run() error      { return nil }
+func (a *testApp) Shutdown() error { return nil }
+func (a *testApp) Addr() string    { return "" }
+func (a *testApp) Name() string    { return a.name }
+
+func appNames(apps []App) []string {
+	var names []string
+	for _, app := range apps {
+		names = append(names, app.Name())
+	}
+	return names
+}
+
+func TestSortApps(t *testing.T) {
+	known := func(name string) bool {
+		return name == "db"
+	}
+	apps := []App{
+		DependsOn(&testApp{"rpc"}, "db", "http"),
+		&testApp{"websocket"},
+		DependsOn(&testApp{"http"}, "db"),
+	}
+
+	sorted, err := sortApps(apps, known)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http", "rpc", "websocket"}, appNames(sorted))
+}
+
+func noneKnown(string) bool {
+	return false
+}
+
+func TestSortApps_Errors(t *testing.T) {
+	_, err := sortApps([]App{
+		DependsOn(&testApp{"a"}, "b"),
+		DependsOn(&testApp{"b"}, "a"),
+	}, noneKnown)
+	assert.EqualError(t, err, "server: dependency cycle: a -> b -> a")
+
+	_, err = sortApps([]App{DependsOn(&testApp{"a"}, "redis")}, noneKnown)
+	assert.EqualError(t, err, `server: a depends on unknown "redis"`)
+
+	_, err = sortApps([]App{&testApp{"a"}, &testApp{"a"}}, noneKnown)
+	assert.EqualError(t, err, `server: duplicate app name "a"`)
+}
+
+func TestUnwrapApp(t *testing.T) {
+	app := DependsOn(DependsOn(&testApp{"a"}, "b"), "c")
+	assert.Equal(t, []string{"b", "c"}, appDependsOn(app))
+
+	_, ok := unwrapApp[Starter](app)
+	assert.False(t, ok)
+}
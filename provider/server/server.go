@@ -1,22 +1,32 @@
 package server
 
 import (
-	"flag"
+	"context"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/signal"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hyper-micro/hyper/config"
 	"github.com/hyper-micro/hyper/errors"
+	"github.com/hyper-micro/hyper/health"
 	"github.com/hyper-micro/hyper/logger"
 )
 
 type Provider interface {
 	RegInit(fs ...RegInitHandler)
 	RegServes(fs ...RegServeHandler) error
+	RegReady(name string, f ReadyFunc)
+	// Health is the registry the server probes, to pass to the providers
+	// that register checks.
+	Health() *health.Registry
+	BeforeShutdown(fs ...ShutdownHook)
+	AfterShutdown(fs ...ShutdownHook)
+	Flags() *Flags
 	Run() error
 }
 
@@ -34,35 +44,68 @@ type RegServeHandler func(config.Config) ([]App, CleanUpHandler, error)
 type RegInitHandler func(config.Config) error
 
 type serverProvider struct {
-	opt             Option
-	apps            []App
-	inits           []RegInitHandler
-	cleanUps        []func()
-	flagSet         *flag.FlagSet
-	configFileFlag  string
-	showHelpFlag    bool
-	showVersionFlag bool
-	inShutdown      bool
-	conf            config.Config
+	opt            Option
+	apps           []App
+	inits          []RegInitHandler
+	health         *health.Registry
+	cleanUps       []func()
+	flags          *Flags
+	sets           [][2]string
+	cmd            *Command
+	cmdFlags       *Flags
+	conf           config.Config
+	inherited      map[string]net.Listener
+	started        []App
+	allStarted     bool
+	startMu        sync.Mutex
+	inShutdown     atomic.Bool
+	shutdownOnce   sync.Once
+	shutdownErr    error
+	shutdownDone   chan struct{}
+	beforeShutdown []ShutdownHook
+	afterShutdown  []ShutdownHook
+	exit           func(code int)
 }
 
 type Option struct {
-	AppName               string
-	AppDesc               string
-	Version               string
-	BuildCommit           string
-	BuildDate             string
-	ShutdownSigs          []os.Signal
+	AppName      string
+	AppDesc      string
+	Version      string
+	BuildCommit  string
+	BuildDate    string
+	ShutdownSigs []os.Signal
+	// UpgradeSigs, such as syscall.SIGUSR2, start a copy of the binary that
+	// inherits the apps' listeners, then drain this process once it is ready.
+	UpgradeSigs           []os.Signal
 	ShutdownDelayDuration time.Duration
+	ShutdownTimeout       time.Duration
+	StartupTimeout        time.Duration
 	ConfigPathType        config.PathType
 	ConfigIgnoreFileName  bool
 	ConfigDefault         string
+	Logger                logger.Logger
+	Health                *health.Registry
+	Flags                 []Flag
+	Commands              []Command
+	// EnvPrefix binds every flag without EnvVars to PREFIX_NAME.
+	EnvPrefix string
+	// Args defaults to os.Args[1:], LookupEnv to os.LookupEnv and Output,
+	// where help and version are printed, to os.Stdout.
+	Args      []string
+	LookupEnv func(key string) (string, bool)
+	Output    io.Writer
 }
 
 func NewProvider(opt Option) (Provider, func(), error) {
 	srv := &serverProvider{
-		opt:     opt,
-		flagSet: flag.NewFlagSet(os.Args[0], flag.ContinueOnError),
+		opt:          opt,
+		exit:         os.Exit,
+		shutdownDone: make(chan struct{}),
+	}
+
+	srv.health = opt.Health
+	if srv.health == nil {
+		srv.health = health.Default()
 	}
 
 	if err := srv.init(); err != nil {
@@ -93,21 +136,38 @@ func (s *serverProvider) RegInit(fs ...RegInitHandler) {
 	s.inits = append(s.inits, fs...)
 }
 
-func (s *serverProvider) Run() error {
-	if len(s.opt.ShutdownSigs) > 0 {
-		shutdownSignChan := make(chan os.Signal, 1)
-		signal.Notify(shutdownSignChan, s.opt.ShutdownSigs...)
-		go func() {
-			recSign := <-shutdownSignChan
-			s.stdLoggerPrint("Receive signal: %v", recSign)
+func (s *serverProvider) Health() *health.Registry {
+	return s.health
+}
 
-			if s.opt.ShutdownDelayDuration > 0 {
-				s.stdLoggerPrint("ShutdownDelayDuration %s", s.opt.ShutdownDelayDuration.String())
-				time.Sleep(s.opt.ShutdownDelayDuration)
-			}
+// RegReady registers a named readiness check, such as a database ping, that
+// apps can name in DependsOn to delay their startup until it passes.
+func (s *serverProvider) RegReady(name string, f ReadyFunc) {
+	s.health.Register(name, health.Readiness, health.CheckerFunc(f))
+}
 
-			s.shutdown()
-		}()
+func (s *serverProvider) Flags() *Flags {
+	return s.flags
+}
+
+func (s *serverProvider) Run() error {
+	if s.helpRequested() {
+		s.printUsage()
+		return nil
+	}
+	if s.flags.Bool("version") {
+		s.printVersion()
+		return nil
+	}
+	if s.cmd != nil {
+		return s.runCommand()
+	}
+
+	if len(s.opt.ShutdownSigs) > 0 {
+		s.handleSignals()
+	}
+	if len(s.opt.UpgradeSigs) > 0 {
+		s.handleUpgradeSignals()
 	}
 
 	defer func() {
@@ -125,100 +185,217 @@ func (s *serverProvider) Run() error {
 		}
 	}
 
+	apps, err := sortApps(s.apps, s.health.Has)
+	if err != nil {
+		return err
+	}
+
+	if addr := s.conf.GetString("server.admin.addr"); addr != "" {
+		apps = append([]App{newAdminApp(s, addr, apps)}, apps...)
+	}
+
+	s.health.Register("server", health.Readiness, health.CheckerFunc(s.ready))
+
+	if s.inherited, err = s.inheritListeners(); err != nil {
+		return err
+	}
+
+	startupTimeout := s.opt.StartupTimeout
+	if startupTimeout <= 0 {
+		startupTimeout = defaultStartupTimeout
+	}
+	startCtx, cancelStart := context.WithTimeout(context.Background(), startupTimeout)
+	defer cancelStart()
+
 	var (
 		appErr error
+		errMu  sync.Mutex
 		wg     sync.WaitGroup
 	)
-	for _, app := range s.apps {
+	addErr := func(err error) {
+		errMu.Lock()
+		defer errMu.Unlock()
+		appErr = errors.Wrap(appErr, err)
+	}
+
+	var startErr error
+	for _, app := range apps {
+		s.startMu.Lock()
+		if s.inShutdown.Load() {
+			s.startMu.Unlock()
+			break
+		}
+		s.started = append(s.started, app)
+		s.startMu.Unlock()
+
+		if startErr = s.startApp(startCtx, app); startErr != nil {
+			s.stdErrLoggerPrint("%s start error: %v", app.Name(), startErr)
+			addErr(startErr)
+			break
+		}
+
 		wg.Add(1)
 		go func(app App) {
 			defer wg.Done()
 
-			s.stdLoggerPrint("%s listen: %s", app.Name(), app.Addr())
 			if err := app.Run(); err != nil {
 				s.stdErrLoggerPrint("%s run error: %v", app.Name(), err)
-				appErr = errors.Wrap(appErr, err)
+				addErr(err)
 			}
 
-			s.shutdown()
+			cancelStart()
+			_ = s.shutdown()
 		}(app)
+
+		if startErr = s.waitAppReady(startCtx, app); startErr != nil {
+			s.stdErrLoggerPrint("%s ready error: %v", app.Name(), startErr)
+			addErr(startErr)
+			break
+		}
 	}
 
-	wg.Wait()
+	if startErr == nil {
+		s.startMu.Lock()
+		s.allStarted = !s.inShutdown.Load()
+		s.startMu.Unlock()
+		s.notifyParent()
+	}
+	for name, ln := range s.inherited {
+		s.stdErrLoggerPrint("upgrade: no app took over listener %s", name)
+		_ = ln.Close()
+	}
+
+	if startErr != nil {
+		go func() {
+			_ = s.shutdown()
+		}()
+	}
+
+	appsDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(appsDone)
+	}()
+	select {
+	case <-appsDone:
+	case <-s.shutdownDone:
+		select {
+		case <-appsDone:
+		case <-time.After(appExitGracePeriod):
+			addErr(fmt.Errorf("server: apps still running after shutdown"))
+		}
+	}
+
+	if err := s.shutdown(); err != nil {
+		addErr(err)
+	}
 
 	return appErr
 }
 
-func (s *serverProvider) shutdown() {
-	if s.inShutdown {
-		return
+func (s *serverProvider) startApp(ctx context.Context, app App) error {
+	for _, dep := range appDependsOn(app) {
+		if err := waitReady(ctx, func(ctx context.Context) error {
+			return s.health.CheckOne(ctx, dep)
+		}); err != nil {
+			return fmt.Errorf("waiting for %s: %w", dep, err)
+		}
 	}
 
-	s.inShutdown = true
+	if ln, ok := s.inherited[app.Name()]; ok {
+		if holder, ok := unwrapApp[ListenerHolder](app); ok {
+			holder.SetListener(ln)
+			delete(s.inherited, app.Name())
+		}
+	}
 
-	for _, app := range s.apps {
-		s.stdLoggerPrint("%s shutting down", app.Name())
-		if err := app.Shutdown(); err != nil {
-			s.stdErrLoggerPrint("shutdown failed: %v", err)
+	starter, ok := unwrapApp[Starter](app)
+	if !ok {
+		if app.Addr() == "" {
+			s.stdLoggerPrint("%s starting", app.Name())
+		} else {
+			s.stdLoggerPrint("%s starting: %s", app.Name(), app.Addr())
 		}
+		return nil
 	}
-	for _, cleanUp := range s.cleanUps {
-		cleanUp()
+	if err := starter.Start(ctx); err != nil {
+		return err
 	}
+	s.stdLoggerPrint("%s listen: %s", app.Name(), app.Addr())
+	return nil
 }
 
-func (s *serverProvider) init() error {
-	s.flagSet.Usage = func() {}
-	s.flagSet.SetOutput(io.Discard)
-	s.flagSet.StringVar(&s.configFileFlag, "c", s.opt.ConfigDefault, "set configure file path")
-	s.flagSet.StringVar(&s.configFileFlag, "config", s.opt.ConfigDefault, "set configure file path")
-	s.flagSet.BoolVar(&s.showHelpFlag, "h", false, "show help")
-	s.flagSet.BoolVar(&s.showHelpFlag, "help", false, "show help")
-	s.flagSet.BoolVar(&s.showVersionFlag, "v", false, "show version")
-	s.flagSet.BoolVar(&s.showVersionFlag, "version", false, "show version")
-
-	if err := s.flagSet.Parse(os.Args[1:]); err != nil {
-		return err
+func (s *serverProvider) ready(context.Context) error {
+	s.startMu.Lock()
+	defer s.startMu.Unlock()
+	if s.inShutdown.Load() {
+		return fmt.Errorf("%s shutting down", s.opt.AppName)
+	}
+	if !s.allStarted {
+		return fmt.Errorf("%s starting", s.opt.AppName)
 	}
+	return nil
+}
 
-	if s.showHelpFlag {
-		s.flagUsage()
-		os.Exit(0)
+func (s *serverProvider) waitAppReady(ctx context.Context, app App) error {
+	checker, ok := unwrapApp[ReadyChecker](app)
+	if !ok {
+		return nil
 	}
+	return waitReady(ctx, checker.Ready)
+}
 
-	if s.showVersionFlag {
-		fmt.Printf("Version %s, build %s, %s\n", s.opt.Version, s.opt.BuildCommit, s.opt.BuildDate)
-		os.Exit(0)
+func (s *serverProvider) runCommand() error {
+	for _, init := range s.inits {
+		if err := init(s.conf); err != nil {
+			return err
+		}
 	}
 
-	conf, err := config.New(s.opt.ConfigPathType, s.opt.ConfigIgnoreFileName, s.configFileFlag)
-	if err != nil {
-		return err
+	ctx := context.Background()
+	if len(s.opt.ShutdownSigs) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, s.opt.ShutdownSigs...)
+		defer stop()
 	}
-	s.conf = conf
 
-	return nil
+	err := s.cmd.Action(ctx, s.conf, s.cmdFlags)
+
+	for i := len(s.cleanUps) - 1; i >= 0; i-- {
+		s.cleanUps[i]()
+	}
+	return err
 }
 
-func (s *serverProvider) flagUsage() {
-	fmt.Printf(`
-USAGE:
-   %s [options]
+func (s *serverProvider) init() error {
+	if err := s.parseArgs(); err != nil {
+		return err
+	}
 
-%s
+	conf, err := config.New(s.opt.ConfigPathType, s.opt.ConfigIgnoreFileName, s.flags.String("config"))
+	if err != nil {
+		// Help and version must work without a readable configuration.
+		if !s.helpRequested() && !s.flags.Bool("version") {
+			return err
+		}
+	}
+	s.conf = conf
+	s.applyConfigOverrides()
 
-OPTIONS:
-   --config value, -c value  set configure file path (default: "%s")
-   --version, -v             show version (default: false)
-   --help, -h                show help (default: false)
+	return nil
+}
 
-`, s.opt.AppName, s.opt.AppDesc, s.opt.ConfigDefault)
+func (s *serverProvider) logger() logger.Logger {
+	if s.opt.Logger != nil {
+		return s.opt.Logger
+	}
+	return logger.Default()
 }
 
 func (s *serverProvider) stdLoggerPrint(format string, args ...any) {
-	logger.Infof("[%s] %s", s.opt.AppName, fmt.Sprintf(format, args...))
+	s.logger().Infof("[%s] %s", s.opt.AppName, fmt.Sprintf(format, args...))
 }
 
 func (s *serverProvider) stdErrLoggerPrint(format string, args ...any) {
-	logger.Errorf("[%s] %s", s.opt.AppName, fmt.Sprintf(format, args...))
+	s.logger().Errorf("[%s] %s", s.opt.AppName, fmt.Sprintf(format, args...))
 }
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/hyper-micro/hyper/errors"
+)
+
+const (
+	defaultShutdownTimeout = 30 * time.Second
+	appExitGracePeriod     = time.Second
+)
+
+// GracefulShutdowner is implemented by apps whose shutdown can be bounded by
+// the shutdown deadline. Apps without it have Shutdown raced against it.
+type GracefulShutdowner interface {
+	ShutdownContext(ctx context.Context) error
+}
+
+type ShutdownHook func(ctx context.Context) error
+
+// BeforeShutdown registers hooks run before apps stop accepting traffic,
+// e.g. to deregister from service discovery.
+func (s *serverProvider) BeforeShutdown(fs ...ShutdownHook) {
+	s.beforeShutdown = append(s.beforeShutdown, fs...)
+}
+
+// AfterShutdown registers hooks run once all apps and resources are released.
+func (s *serverProvider) AfterShutdown(fs ...ShutdownHook) {
+	s.afterShutdown = append(s.afterShutdown, fs...)
+}
+
+func (s *serverProvider) handleSignals() {
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, s.opt.ShutdownSigs...)
+	go func() {
+		recSign := <-sigChan
+		s.stdLoggerPrint("Receive signal: %v", recSign)
+		go func() {
+			_ = s.shutdown()
+		}()
+
+		recSign = <-sigChan
+		s.stdErrLoggerPrint("Receive signal: %v again, force exit", recSign)
+		s.exit(1)
+	}()
+}
+
+// shutdown runs the shutdown pipeline once; concurrent callers block until it
+// finishes and all receive its error.
+func (s *serverProvider) shutdown() error {
+	s.shutdownOnce.Do(func() {
+		s.shutdownErr = s.runShutdown()
+		close(s.shutdownDone)
+	})
+	return s.shutdownErr
+}
+
+func (s *serverProvider) runShutdown() error {
+	s.startMu.Lock()
+	s.inShutdown.Store(true)
+	apps := append([]App(nil), s.started...)
+	s.startMu.Unlock()
+
+	timeout := s.opt.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var shutdownErr error
+
+	s.stdLoggerPrint("Shutdown: stop accepting")
+	for _, hook := range s.beforeShutdown {
+		if err := hook(ctx); err != nil {
+			s.stdErrLoggerPrint("before shutdown hook failed: %v", err)
+			shutdownErr = errors.Wrap(shutdownErr, err)
+		}
+	}
+
+	if s.opt.ShutdownDelayDuration > 0 {
+		s.stdLoggerPrint("Shutdown: drain %s", s.opt.ShutdownDelayDuration.String())
+		select {
+		case <-time.After(s.opt.ShutdownDelayDuration):
+		case <-ctx.Done():
+		}
+	}
+
+	s.stdLoggerPrint("Shutdown: close apps")
+	for i := len(apps) - 1; i >= 0; i-- {
+		app := apps[i]
+		s.stdLoggerPrint("%s shutting down", app.Name())
+		if err := shutdownApp(ctx, app); err != nil {
+			s.stdErrLoggerPrint("%s shutdown failed: %v", app.Name(), err)
+			shutdownErr = errors.Wrap(shutdownErr, fmt.Errorf("%s shutdown: %w", app.Name(), err))
+		}
+	}
+
+	s.stdLoggerPrint("Shutdown: release resources")
+	for i := len(s.cleanUps) - 1; i >= 0; i-- {
+		if err := runWithContext(ctx, func() error {
+			s.cleanUps[i]()
+			return nil
+		}); err != nil {
+			s.stdErrLoggerPrint("cleanup failed: %v", err)
+			shutdownErr = errors.Wrap(shutdownErr, fmt.Errorf("cleanup: %w", err))
+			break
+		}
+	}
+
+	for _, hook := range s.afterShutdown {
+		if err := hook(ctx); err != nil {
+			s.stdErrLoggerPrint("after shutdown hook failed: %v", err)
+			shutdownErr = errors.Wrap(shutdownErr, err)
+		}
+	}
+
+	return shutdownErr
+}
+
+func shutdownApp(ctx context.Context, app App) error {
+	if gs, ok := unwrapApp[GracefulShutdowner](app); ok {
+		return gs.ShutdownContext(ctx)
+	}
+	return runWithContext(ctx, app.Shutdown)
+}
+
+// runWithContext runs f and returns early with the context error if ctx
+// expires first; f keeps running in the background in that case.
+func runWithContext(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- f()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	stdErrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) add(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+type blockingApp struct {
+	testApp
+	rec  *recorder
+	stop chan struct{}
+	err  error
+}
+
+func newBlockingApp(name string, rec *recorder) *blockingApp {
+	return &blockingApp{testApp: testApp{name}, rec: rec, stop: make(chan struct{})}
+}
+
+func (a *blockingApp) Run() error {
+	<-a.stop
+	return nil
+}
+
+func (a *blockingApp) Shutdown() error {
+	a.rec.add("shutdown " + a.name)
+	close(a.stop)
+	return a.err
+}
+
+func newTestProvider(t *testing.T, opt Option) *serverProvider {
+	if opt.Logger == nil {
+		opt.Logger = loggertest.New()
+	}
+	conf, err := config.New(config.PathTypePath, false, t.TempDir())
+	require.NoError(t, err)
+	return &serverProvider{
+		opt:          opt,
+		conf:         conf,
+		health:       health.NewRegistry(),
+		exit:         func(int) {},
+		shutdownDone: make(chan struct{}),
+	}
+}
+
+func TestShutdown_ReverseOrder(t *testing.T) {
+	rec := new(recorder)
+	s := newTestProvider(t, Option{})
+
+	first, second := newBlockingApp("first", rec), newBlockingApp("second", rec)
+	second.err = stdErrors.New("boom")
+	s.apps = []App{first, second}
+	s.cleanUps = []func(){
+		func() { rec.add("cleanup first") },
+		func() { rec.add("cleanup second") },
+	}
+	s.BeforeShutdown(func(context.Context) error {
+		rec.add("before")
+		return nil
+	})
+	s.AfterShutdown(func(context.Context) error {
+		rec.add("after")
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run()
+	}()
+
+	assert.Eventually(t, func() bool {
+		s.startMu.Lock()
+		defer s.startMu.Unlock()
+		return len(s.started) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.EqualError(t, s.shutdown(), "second shutdown: boom")
+	assert.EqualError(t, <-done, "second shutdown: boom")
+	assert.Equal(t, []string{
+		"before",
+		"shutdown second",
+		"shutdown first",
+		"cleanup second",
+		"cleanup first",
+		"after",
+	}, rec.calls)
+}
+
+func TestShutdown_Deadline(t *testing.T) {
+	s := newTestProvider(t, Option{ShutdownTimeout: 50 * time.Millisecond})
+	s.cleanUps = []func(){
+		func() { time.Sleep(time.Second) },
+	}
+
+	err := s.shutdown()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables describing the files a parent process hands to its
+// replacement. Inherited files start at descriptor 3, in the order of
+// envListeners, followed by the readiness pipe.
+const (
+	envListeners = "HYPER_LISTENERS"
+	envReadyFD   = "HYPER_READY_FD"
+	firstFD      = 3
+)
+
+// ListenerHolder is implemented by apps whose listener can be handed over
+// to a new process during a zero-downtime restart.
+type ListenerHolder interface {
+	Listener() net.Listener
+	SetListener(ln net.Listener)
+}
+
+type fileListener interface {
+	File() (*os.File, error)
+}
+
+// inheritListeners reads the listeners passed by a parent process, keyed by
+// app name.
+func (s *serverProvider) inheritListeners() (map[string]net.Listener, error) {
+	names, ok := s.lookupEnv(envListeners)
+	if !ok || names == "" {
+		return nil, nil
+	}
+
+	listeners := make(map[string]net.Listener)
+	for i, name := range strings.Split(names, ",") {
+		f := os.NewFile(uintptr(firstFD+i), name)
+		ln, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("upgrade: inherit listener %s: %w", name, err)
+		}
+		listeners[name] = ln
+	}
+	return listeners, nil
+}
+
+// notifyParent tells the parent process, if any, that this process has
+// started all apps and the parent can drain.
+func (s *serverProvider) notifyParent() {
+	fdText, ok := s.lookupEnv(envReadyFD)
+	if !ok {
+		return
+	}
+	fd, err := strconv.Atoi(fdText)
+	if err != nil {
+		s.stdErrLoggerPrint("upgrade: invalid %s %q", envReadyFD, fdText)
+		return
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	defer f.Close()
+	if _, err := f.Write([]byte{1}); err != nil {
+		s.stdErrLoggerPrint("upgrade: notify parent: %v", err)
+	}
+}
+
+func (s *serverProvider) handleUpgradeSignals() {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, s.opt.UpgradeSigs...)
+	go func() {
+		for recSign := range sigChan {
+			if s.inShutdown.Load() {
+				return
+			}
+			s.stdLoggerPrint("Receive signal: %v, upgrading", recSign)
+			if err := s.upgrade(); err != nil {
+				s.stdErrLoggerPrint("upgrade failed: %v", err)
+				continue
+			}
+			go func() {
+				_ = s.shutdown()
+			}()
+			return
+		}
+	}()
+}
+
+// upgrade starts a copy of the current binary with the apps' listeners,
+// waiting until it reports that all apps are started.
+func (s *serverProvider) upgrade() error {
+	s.startMu.Lock()
+	apps := append([]App(nil), s.started...)
+	s.startMu.Unlock()
+
+	var (
+		names []string
+		files []*os.File
+	)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, app := range apps {
+		holder, ok := unwrapApp[ListenerHolder](app)
+		if !ok || holder.Listener() == nil {
+			continue
+		}
+		// Listeners without a file, such as those of a mux, are derived
+		// from another app's listener and rebuilt by the child.
+		fl, ok := holder.Listener().(fileListener)
+		if !ok {
+			continue
+		}
+		f, err := fl.File()
+		if err != nil {
+			return fmt.Errorf("%s: %w", app.Name(), err)
+		}
+		names = append(names, app.Name())
+		files = append(files, f)
+	}
+
+	readyR, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyR.Close()
+	files = append(files, readyW)
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := s.opt.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(upgradeEnviron(),
+		envListeners+"="+strings.Join(names, ","),
+		envReadyFD+"="+strconv.Itoa(firstFD+len(files)-1),
+	)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Only the child may hold the write end, so that its exit closes the pipe.
+	_ = readyW.Close()
+	files = files[:len(files)-1]
+
+	timeout := s.opt.StartupTimeout
+	if timeout <= 0 {
+		timeout = defaultStartupTimeout
+	}
+	ready := make(chan error, 1)
+	go func() {
+		b := make([]byte, 1)
+		_, err := io.ReadFull(readyR, b)
+		ready <- err
+	}()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return fmt.Errorf("child %d exited before ready: %w", cmd.Process.Pid, err)
+		}
+	case <-time.After(timeout):
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("child %d not ready after %s", cmd.Process.Pid, timeout)
+	}
+
+	s.stdLoggerPrint("upgrade: child %d ready, draining", cmd.Process.Pid)
+	return cmd.Process.Release()
+}
+
+func upgradeEnviron() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envListeners+"=") || strings.HasPrefix(kv, envReadyFD+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+func (s *serverProvider) lookupEnv(key string) (string, bool) {
+	if s.opt.LookupEnv != nil {
+		return s.opt.LookupEnv(key)
+	}
+	return os.LookupEnv(key)
+}
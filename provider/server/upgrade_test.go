@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgrade_InheritedListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := newTestProvider(t, Option{})
+	s.inherited = map[string]net.Listener{"admin": ln}
+
+	app := newAdminApp(s, "127.0.0.1:0", nil)
+	require.NoError(t, s.startApp(context.Background(), app))
+	assert.Same(t, ln, app.Listener())
+	assert.Empty(t, s.inherited)
+
+	_, isFile := app.Listener().(fileListener)
+	assert.True(t, isFile)
+	require.NoError(t, ln.Close())
+}
+
+func TestUpgrade_NoParent(t *testing.T) {
+	s := newTestProvider(t, Option{
+		LookupEnv: func(string) (string, bool) { return "", false },
+	})
+	listeners, err := s.inheritListeners()
+	require.NoError(t, err)
+	assert.Nil(t, listeners)
+	s.notifyParent()
+}
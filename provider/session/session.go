@@ -0,0 +1,92 @@
+package session
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/provider/redis"
+	"github.com/hyper-micro/hyper/server/web"
+	"github.com/hyper-micro/hyper/session"
+)
+
+type Provider interface {
+	Into() session.Store
+	// Option returns the session settings for web.Option.Session.
+	Option() *web.SessionOption
+}
+
+type sessionProvider struct {
+	store session.Store
+	opt   *web.SessionOption
+}
+
+// NewProvider builds the store configured by server.session.store:
+//
+//	cookie   the default, signing cookies with server.session.hashKey and
+//	         encrypting them with server.session.blockKey, if set
+//	memory
+//	redis    using the client of the server.session.redis instance of rdb,
+//	         prefixing keys with server.session.prefix
+//
+// The cookie is set by server.session.cookieName, maxAge, path, domain,
+// secure and sameSite (lax, strict or none). rdb may be nil for stores
+// other than redis.
+func NewProvider(conf config.Config, rdb redis.Provider) (Provider, error) {
+	var store session.Store
+	switch kind := conf.GetStringOrDefault("server.session.store", "cookie"); kind {
+	case "cookie":
+		s, err := session.NewCookieStore(
+			[]byte(conf.GetString("server.session.hashKey")),
+			[]byte(conf.GetString("server.session.blockKey")),
+		)
+		if err != nil {
+			return nil, err
+		}
+		store = s
+	case "memory":
+		store = session.NewMemoryStore()
+	case "redis":
+		if rdb == nil {
+			return nil, fmt.Errorf("session: redis store requires the redis provider")
+		}
+		client := rdb.Into(conf.GetStringOrDefault("server.session.redis", "default"))
+		store = session.NewRedisStore(client, conf.GetString("server.session.prefix"))
+	default:
+		return nil, fmt.Errorf("session: unknown store %q", kind)
+	}
+
+	var sameSite http.SameSite
+	switch s := strings.ToLower(conf.GetString("server.session.sameSite")); s {
+	case "", "lax":
+		sameSite = http.SameSiteLaxMode
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("session: unknown sameSite %q", s)
+	}
+
+	return &sessionProvider{
+		store: store,
+		opt: &web.SessionOption{
+			Store:      store,
+			CookieName: conf.GetString("server.session.cookieName"),
+			MaxAge:     conf.GetDuration("server.session.maxAge"),
+			Path:       conf.GetString("server.session.path"),
+			Domain:     conf.GetString("server.session.domain"),
+			Secure:     conf.GetBool("server.session.secure"),
+			SameSite:   sameSite,
+		},
+	}, nil
+}
+
+func (p *sessionProvider) Into() session.Store {
+	return p.store
+}
+
+func (p *sessionProvider) Option() *web.SessionOption {
+	return p.opt
+}
@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/tracing"
+)
+
+type Provider interface {
+	Into() *tracing.Tracer
+}
+
+type tracingProvider struct {
+	tracer *tracing.Tracer
+}
+
+// NewProvider installs the tracer configured under trace.tracing as
+// tracing.Default(). Construct it before the server, db and redis
+// providers, which capture the default tracer when they are created.
+func NewProvider(conf config.Config) (Provider, func(), error) {
+	var (
+		exporter tracing.Exporter
+		err      error
+	)
+	switch kind := conf.GetStringOrDefault("trace.tracing.exporter", "none"); kind {
+	case "none", "":
+	case "stdout":
+		exporter = tracing.NewStdoutExporter()
+	case "file":
+		exporter, err = tracing.NewFileExporter(conf.GetStringOrDefault("trace.tracing.path", "trace.json"))
+		if err != nil {
+			return nil, nil, err
+		}
+	case "otlp":
+		exporter = tracing.NewOTLPExporter(tracing.OTLPOption{
+			Endpoint: conf.GetStringOrDefault("trace.tracing.endpoint", "http://localhost:4318/v1/traces"),
+			Headers:  conf.GetStringMapString("trace.tracing.headers"),
+			Timeout:  conf.GetDurationOrDefault("trace.tracing.timeout", 10*time.Second),
+		})
+	default:
+		return nil, nil, fmt.Errorf("trace.tracing.exporter not supported, exporter = {%s}", kind)
+	}
+
+	instance := tracing.New(tracing.Option{
+		ServiceName:   conf.GetString("trace.tracing.serviceName"),
+		Exporter:      exporter,
+		Sampler:       tracing.RatioSampler(conf.GetFloat64OrDefault("trace.tracing.sampleRatio", 1)),
+		BatchSize:     conf.GetInt("trace.tracing.batchSize"),
+		FlushInterval: conf.GetDuration("trace.tracing.flushInterval"),
+	})
+	prev := tracing.Default()
+	tracing.SetDefault(instance)
+
+	return &tracingProvider{tracer: instance}, func() {
+		tracing.SetDefault(prev)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = instance.Shutdown(ctx)
+	}, nil
+}
+
+func (p *tracingProvider) Into() *tracing.Tracer {
+	return p.tracer
+}
@@ -1,28 +1,45 @@
 package websocket
 
 import (
+	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/hyper-micro/hyper/config"
+	"github.com/hyper-micro/hyper/health"
+	"github.com/hyper-micro/hyper/metrics"
 	"github.com/hyper-micro/hyper/provider/logger"
 	"github.com/hyper-micro/hyper/server/websocket"
 )
 
 type Provider interface {
 	Into() *websocket.Server
+	Start(ctx context.Context) error
+	Ready(ctx context.Context) error
+	Listener() net.Listener
+	SetListener(ln net.Listener)
 	Run() error
 	Shutdown() error
+	ShutdownContext(ctx context.Context) error
 	Addr() string
+	Name() string
 }
 
 type websocketProvider struct {
-	addr string
-	srv  *websocket.Server
+	addr   string
+	srv    *websocket.Server
+	health *health.Registry
 }
 
-func NewProvider(conf config.Config, logger logger.Provider) Provider {
-	addr := conf.GetStringOrDefault("server.websocket.addr", "0.0.0.0:18110")
+// NewProvider builds the server of server.websocket. registry receives the
+// readiness check and defaults to health.Default().
+func NewProvider(conf config.Config, registry *health.Registry, logger logger.Provider) Provider {
+	if registry == nil {
+		registry = health.Default()
+	}
+	addr := conf.GetStringOrDefault("server.websocket.addr", "0.0.0.0:18111")
 	readTimeout := conf.GetDurationOrDefault("server.websocket.readTimeout", time.Second)
 	readBuffer := conf.GetIntOrDefault("server.websocket.readBuffer", 32*1024)
 	opt := websocket.Option{
@@ -45,11 +62,18 @@ func NewProvider(conf config.Config, logger logger.Provider) Provider {
 		MessageType: websocket.BinaryMessage,
 	}
 
+	if conf.GetBoolOrDefault("server.websocket.metrics", true) {
+		opt.Metrics = metrics.Default()
+	}
+
 	p := &websocketProvider{
-		addr: addr,
-		srv:  websocket.New(opt),
+		addr:   addr,
+		srv:    websocket.New(opt),
+		health: registry,
 	}
 
+	p.health.Register("websocket", health.Readiness, health.CheckerFunc(p.Ready))
+
 	return p
 }
 
@@ -57,14 +81,43 @@ func (p *websocketProvider) Into() *websocket.Server {
 	return p.srv
 }
 
+func (p *websocketProvider) Start(ctx context.Context) error {
+	return p.srv.Listen(ctx)
+}
+
+func (p *websocketProvider) Ready(ctx context.Context) error {
+	if !p.srv.Listening() {
+		return fmt.Errorf("websocket: not listening on %s", p.addr)
+	}
+	return nil
+}
+
+func (p *websocketProvider) Listener() net.Listener {
+	return p.srv.Listener()
+}
+
+func (p *websocketProvider) SetListener(ln net.Listener) {
+	p.srv.SetListener(ln)
+}
+
 func (p *websocketProvider) Run() error {
 	return p.srv.Run()
 }
 
 func (p *websocketProvider) Shutdown() error {
+	p.health.Unregister("websocket")
 	return p.srv.Shutdown()
 }
 
+func (p *websocketProvider) ShutdownContext(ctx context.Context) error {
+	p.health.Unregister("websocket")
+	return p.srv.ShutdownContext(ctx)
+}
+
 func (p *websocketProvider) Addr() string {
 	return p.addr
 }
+
+func (p *websocketProvider) Name() string {
+	return "websocket"
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps limits in process memory, for single instances.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type entry struct {
+	// token bucket
+	tokens float64
+	ts     float64
+	// sliding window
+	win       int64
+	cur, prev float64
+
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+}
+
+// sweepInterval bounds how often expired keys are dropped.
+const sweepInterval = time.Minute
+
+func (s *MemoryStore) Allow(_ context.Context, key string, alg Algorithm, rate Rate) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > sweepInterval {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	nowMs := float64(now.UnixMicro()) / 1000
+
+	e, ok := s.entries[key]
+	if !ok || now.After(e.expires) {
+		e = &entry{tokens: rate.capacity(), ts: nowMs, win: -1}
+		s.entries[key] = e
+	}
+
+	if alg == SlidingWindow {
+		window := rate.Period.Milliseconds()
+		idx := int64(nowMs) / window
+		switch {
+		case idx > e.win+1:
+			e.cur, e.prev = 0, 0
+		case idx == e.win+1:
+			e.cur, e.prev = 0, e.cur
+		}
+		e.win = idx
+		elapsed := nowMs - float64(idx*window)
+		count := e.prev*(float64(window)-elapsed)/float64(window) + e.cur
+		allowed := count < float64(rate.Limit)
+		if allowed {
+			e.cur++
+			count++
+		}
+		e.expires = now.Add(2 * rate.Period)
+		return windowResult(rate, count, e.cur, e.prev, elapsed, allowed), nil
+	}
+
+	e.tokens = math.Min(rate.capacity(), e.tokens+math.Max(0, nowMs-e.ts)*rate.perMilli())
+	e.ts = nowMs
+	allowed := e.tokens >= 1
+	if allowed {
+		e.tokens--
+	}
+	res := bucketResult(rate, e.tokens, allowed)
+	e.expires = now.Add(res.Reset + time.Second)
+	return res, nil
+}
@@ -0,0 +1,158 @@
+// Package ratelimit limits how often a key, such as a client address or
+// user, may act, using a token bucket or sliding window kept in memory or
+// in Redis.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+type Algorithm int
+
+const (
+	// TokenBucket refills Rate.Limit tokens per Rate.Period into a bucket
+	// holding up to Rate.Burst, allowing short bursts.
+	TokenBucket Algorithm = iota
+	// SlidingWindow allows Rate.Limit requests in any Rate.Period,
+	// approximated from the counts of the current and previous windows.
+	SlidingWindow
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case TokenBucket:
+		return "tokenBucket"
+	case SlidingWindow:
+		return "slidingWindow"
+	}
+	return fmt.Sprintf("Algorithm(%d)", int(a))
+}
+
+type Rate struct {
+	Limit  int
+	Period time.Duration
+	// Burst is the token bucket capacity and defaults to Limit.
+	Burst int
+}
+
+func PerSecond(n int) Rate {
+	return Rate{Limit: n, Period: time.Second}
+}
+
+func PerMinute(n int) Rate {
+	return Rate{Limit: n, Period: time.Minute}
+}
+
+func PerHour(n int) Rate {
+	return Rate{Limit: n, Period: time.Hour}
+}
+
+func (r Rate) capacity() float64 {
+	if r.Burst > 0 {
+		return float64(r.Burst)
+	}
+	return float64(r.Limit)
+}
+
+// perMilli is the token bucket refill rate in tokens per millisecond.
+func (r Rate) perMilli() float64 {
+	return float64(r.Limit) / float64(r.Period.Milliseconds())
+}
+
+type Result struct {
+	Allowed bool
+	// Limit is the number of requests allowed at once: the bucket capacity
+	// or the window limit.
+	Limit     int
+	Remaining int
+	// Reset is the time until the limit is fully available again.
+	Reset time.Duration
+	// RetryAfter is the time until a denied request would be allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the state of limited keys.
+type Store interface {
+	Allow(ctx context.Context, key string, alg Algorithm, rate Rate) (Result, error)
+}
+
+type Option struct {
+	// Name prefixes keys, keeping limiters sharing a store apart.
+	Name      string
+	Rate      Rate
+	Algorithm Algorithm
+	// Store defaults to a new MemoryStore.
+	Store Store
+}
+
+type Limiter struct {
+	opt Option
+}
+
+// New returns a limiter. It panics if the rate is not positive.
+func New(opt Option) *Limiter {
+	if opt.Rate.Limit <= 0 || opt.Rate.Period < time.Millisecond {
+		panic(fmt.Sprintf("ratelimit: invalid rate %d per %s", opt.Rate.Limit, opt.Rate.Period))
+	}
+	if opt.Store == nil {
+		opt.Store = NewMemoryStore()
+	}
+	return &Limiter{opt: opt}
+}
+
+// Allow takes one request for key from the limit.
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	if l.opt.Name != "" {
+		key = l.opt.Name + ":" + key
+	}
+	return l.opt.Store.Allow(ctx, key, l.opt.Algorithm, l.opt.Rate)
+}
+
+func (l *Limiter) Rate() Rate {
+	return l.opt.Rate
+}
+
+func millis(ms float64) time.Duration {
+	return time.Duration(math.Ceil(ms)) * time.Millisecond
+}
+
+// bucketResult describes a token bucket left with tokens.
+func bucketResult(rate Rate, tokens float64, allowed bool) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     int(rate.capacity()),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     millis((rate.capacity() - tokens) / rate.perMilli()),
+	}
+	if !allowed {
+		res.RetryAfter = millis((1 - tokens) / rate.perMilli())
+	}
+	return res
+}
+
+// windowResult describes a sliding window whose weighted count is count,
+// with cur and prev requests in the current and previous windows and
+// elapsed milliseconds into the current one.
+func windowResult(rate Rate, count, cur, prev, elapsed float64, allowed bool) Result {
+	limit := float64(rate.Limit)
+	window := float64(rate.Period.Milliseconds())
+	res := Result{
+		Allowed:   allowed,
+		Limit:     rate.Limit,
+		Remaining: int(math.Max(0, math.Floor(limit-count))),
+		Reset:     millis(window - elapsed),
+	}
+	if !allowed {
+		if cur >= limit {
+			// The current window becomes the previous one, whose weight
+			// must decay below the limit.
+			res.RetryAfter = millis(window - elapsed + window*(1-limit/cur))
+		} else {
+			res.RetryAfter = millis(math.Max(1, window*(1-(limit-cur)/prev)-elapsed))
+		}
+	}
+	return res
+}
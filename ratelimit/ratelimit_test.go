@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func newTestStore() (*MemoryStore, *clock) {
+	c := &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	s := NewMemoryStore()
+	s.now = c.now
+	return s, c
+}
+
+func TestTokenBucket(t *testing.T) {
+	store, clock := newTestStore()
+	l := New(Option{Name: "api", Rate: Rate{Limit: 2, Period: time.Second, Burst: 3}, Store: store})
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		res, err := l.Allow(ctx, "1.2.3.4")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, i, res.Remaining)
+	}
+	res, _ := l.Allow(ctx, "1.2.3.4")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, res.Reset)
+
+	res, _ = l.Allow(ctx, "5.6.7.8")
+	assert.True(t, res.Allowed)
+
+	clock.t = clock.t.Add(500 * time.Millisecond)
+	res, _ = l.Allow(ctx, "1.2.3.4")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 0, res.Remaining)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	store, clock := newTestStore()
+	l := New(Option{Rate: PerMinute(4), Algorithm: SlidingWindow, Store: store})
+	ctx := context.Background()
+
+	clock.t = clock.t.Add(30 * time.Second)
+	for i := 0; i < 4; i++ {
+		res, _ := l.Allow(ctx, "user")
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3-i, res.Remaining)
+	}
+	res, _ := l.Allow(ctx, "user")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 30*time.Second, res.Reset)
+	assert.Equal(t, 30*time.Second, res.RetryAfter)
+
+	// Halfway into the next window the previous 4 requests weigh 2.
+	clock.t = clock.t.Add(time.Minute)
+	res, _ = l.Allow(ctx, "user")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 1, res.Remaining)
+	res, _ = l.Allow(ctx, "user")
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, "user")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, time.Millisecond, res.RetryAfter)
+
+	clock.t = clock.t.Add(3 * time.Minute)
+	res, _ = l.Allow(ctx, "user")
+	assert.True(t, res.Allowed)
+	assert.Equal(t, 3, res.Remaining)
+	assert.Len(t, store.entries, 1)
+}
+
+func TestNew_InvalidRate(t *testing.T) {
+	assert.Panics(t, func() {
+		New(Option{Rate: Rate{Limit: 1}})
+	})
+}
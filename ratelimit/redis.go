@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The scripts take the time from the Redis server, so that instances with
+// skewed clocks share one limit.
+var (
+	tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+	slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+local idx = math.floor(now / window)
+local state = redis.call('HMGET', KEYS[1], 'win', 'cur', 'prev')
+local win = tonumber(state[1])
+local cur = tonumber(state[2]) or 0
+local prev = tonumber(state[3]) or 0
+if win == nil or idx > win + 1 then
+  cur = 0
+  prev = 0
+elseif idx == win + 1 then
+  prev = cur
+  cur = 0
+end
+local elapsed = now - idx * window
+local count = prev * (window - elapsed) / window + cur
+local allowed = 0
+if count < limit then
+  cur = cur + 1
+  count = count + 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'win', idx, 'cur', cur, 'prev', prev)
+redis.call('PEXPIRE', KEYS[1], window * 2)
+return {allowed, tostring(count), cur, prev, tostring(elapsed)}
+`)
+)
+
+// RedisStore keeps limits in Redis, sharing them between instances.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore returns a store using client, such as one from the redis
+// provider. Keys are prefixed with prefix, which defaults to "ratelimit:".
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "ratelimit:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, key string, alg Algorithm, rate Rate) (Result, error) {
+	keys := []string{s.prefix + key}
+	if alg == SlidingWindow {
+		vals, err := slidingWindowScript.Run(ctx, s.client, keys, rate.Limit, rate.Period.Milliseconds()).Slice()
+		if err != nil {
+			return Result{}, err
+		}
+		count, cur, prev, elapsed := parseFloat(vals[1]), parseFloat(vals[2]), parseFloat(vals[3]), parseFloat(vals[4])
+		return windowResult(rate, count, cur, prev, elapsed, parseFloat(vals[0]) == 1), nil
+	}
+
+	vals, err := tokenBucketScript.Run(ctx, s.client, keys, rate.capacity(), rate.perMilli()).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return bucketResult(rate, parseFloat(vals[1]), parseFloat(vals[0]) == 1), nil
+}
+
+func parseFloat(v any) float64 {
+	switch v := v.(type) {
+	case int64:
+		return float64(v)
+	case string:
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return 0
+}
@@ -0,0 +1,216 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyper-micro/hyper/logger"
+)
+
+type Job func(ctx context.Context) error
+
+type JobConfig struct {
+	Name string
+	Spec string
+	// Jitter delays every run by a random duration in [0, Jitter) so that
+	// replicas sharing a schedule do not fire at the same instant.
+	Jitter time.Duration
+	// AllowOverlap lets a run start while the previous one is still going.
+	// By default such runs are skipped.
+	AllowOverlap bool
+	RunOnStart   bool
+	Timeout      time.Duration
+}
+
+type Option struct {
+	Name            string
+	Location        *time.Location
+	ShutdownTimeout time.Duration
+	Logger          logger.Logger
+}
+
+type job struct {
+	JobConfig
+	schedule Schedule
+	f        Job
+	running  atomic.Bool
+}
+
+type Scheduler struct {
+	Option
+
+	jobs    []*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	loops   sync.WaitGroup
+	runs    sync.WaitGroup
+	started atomic.Bool
+	stopped chan struct{}
+}
+
+func New(opt Option) *Scheduler {
+	if opt.Name == "" {
+		opt.Name = "cron"
+	}
+	if opt.Location == nil {
+		opt.Location = time.Local
+	}
+	if opt.Logger == nil {
+		opt.Logger = logger.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		Option:  opt,
+		ctx:     ctx,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+	}
+}
+
+// Add registers a job. It must be called before Run.
+func (s *Scheduler) Add(conf JobConfig, f Job) error {
+	schedule, err := parse(conf.Spec, s.Location)
+	if err != nil {
+		return err
+	}
+	if conf.Name == "" {
+		conf.Name = conf.Spec
+	}
+	s.jobs = append(s.jobs, &job{
+		JobConfig: conf,
+		schedule:  schedule,
+		f:         f,
+	})
+	return nil
+}
+
+func (s *Scheduler) Run() error {
+	if !s.started.CompareAndSwap(false, true) {
+		return fmt.Errorf("cron: %s already running", s.Option.Name)
+	}
+	defer close(s.stopped)
+
+	for _, j := range s.jobs {
+		s.loops.Add(1)
+		go s.loop(j)
+	}
+
+	<-s.ctx.Done()
+	s.loops.Wait()
+	s.runs.Wait()
+
+	return nil
+}
+
+func (s *Scheduler) loop(j *job) {
+	defer s.loops.Done()
+
+	if j.RunOnStart {
+		s.fire(j)
+	}
+
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.Logger.Warnf("cron: job %s has no next run, spec: %s", j.Name, j.Spec)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.fire(j)
+	}
+}
+
+func (s *Scheduler) fire(j *job) {
+	if !j.AllowOverlap && !j.running.CompareAndSwap(false, true) {
+		s.Logger.Warnf("cron: job %s still running, skipping", j.Name)
+		return
+	}
+
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		if !j.AllowOverlap {
+			defer j.running.Store(false)
+		}
+
+		if j.Jitter > 0 {
+			timer := time.NewTimer(rand.N(j.Jitter))
+			select {
+			case <-s.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		start := time.Now()
+		if err := s.run(j); err != nil {
+			s.Logger.Errorf("cron: job %s failed after %s: %v", j.Name, time.Since(start), err)
+			return
+		}
+		s.Logger.Debugf("cron: job %s finished in %s", j.Name, time.Since(start))
+	}()
+}
+
+func (s *Scheduler) run(j *job) (err error) {
+	ctx := s.ctx
+	if j.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.Timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return j.f(ctx)
+}
+
+func (s *Scheduler) Shutdown() error {
+	shutdownTimeout := s.ShutdownTimeout
+	if shutdownTimeout == 0 {
+		shutdownTimeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return s.ShutdownContext(ctx)
+}
+
+// ShutdownContext cancels the context passed to running jobs and waits for
+// them to return until ctx expires.
+func (s *Scheduler) ShutdownContext(ctx context.Context) error {
+	s.cancel()
+	if !s.started.Load() {
+		return nil
+	}
+
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) Addr() string {
+	return ""
+}
+
+func (s *Scheduler) Name() string {
+	return s.Option.Name
+}
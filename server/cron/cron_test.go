@@ -0,0 +1,82 @@
+package cron
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Next(t *testing.T) {
+	base := time.Date(2024, time.March, 15, 10, 30, 20, 500, time.UTC)
+
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * * *", time.Date(2024, time.March, 15, 10, 30, 30, 0, time.UTC)},
+		{"0 9-17/4 * * mon-fri", time.Date(2024, time.March, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 1", time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2024, time.March, 15, 10, 31, 50, 0, time.UTC)},
+		{"TZ=Asia/Shanghai 0 8 * * *", time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		schedule, err := parse(c.spec, time.UTC)
+		require.NoError(t, err, c.spec)
+		assert.Equal(t, c.want, schedule.Next(base).UTC(), c.spec)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@every 10ms",
+		"TZ=Nowhere/City * * * * *",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestScheduler_RunOnStartAndShutdown(t *testing.T) {
+	log := loggertest.New()
+	s := New(Option{Logger: log})
+
+	var runs atomic.Int32
+	require.NoError(t, s.Add(JobConfig{
+		Name:       "panics",
+		Spec:       "@every 1h",
+		RunOnStart: true,
+	}, func(ctx context.Context) error {
+		runs.Add(1)
+		panic("boom")
+	}))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run()
+	}()
+
+	assert.Eventually(t, func() bool {
+		return len(log.Entries().FilterLevel(logger.ErrorLevel)) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+	log.AssertLogged(t, logger.ErrorLevel, "panic: boom")
+
+	require.NoError(t, s.Shutdown())
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,333 @@
+// The schedule parser and the Next search are adapted from
+// github.com/robfig/cron, distributed under the following license:
+//
+// Copyright (C) 2012 Rob Figueiredo
+// All Rights Reserved.
+//
+// MIT LICENSE
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Schedule interface {
+	// Next returns the first activation time strictly after t, or the zero
+	// time if there is none within the search horizon.
+	Next(t time.Time) time.Time
+}
+
+type field struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondField = field{min: 0, max: 59}
+	minuteField = field{min: 0, max: 59}
+	hourField   = field{min: 0, max: 23}
+	domField    = field{min: 1, max: 31}
+	monthField  = field{min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = field{min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// starBit marks a field written as "*" or "?", which matters for the
+// day-of-month/day-of-week combination rule.
+const starBit = 1 << 63
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+type specSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	loc                                   *time.Location
+}
+
+type everySchedule struct {
+	every time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.every - time.Duration(t.Nanosecond())*time.Nanosecond)
+}
+
+// Parse parses a cron expression. It accepts the standard five fields
+// (minute hour day-of-month month day-of-week), an optional leading seconds
+// field, the descriptors @yearly, @monthly, @weekly, @daily, @hourly and
+// "@every <duration>". A leading "TZ=<zone>" or "CRON_TZ=<zone>" selects the
+// location the expression is evaluated in.
+func Parse(spec string) (Schedule, error) {
+	return parse(spec, time.Local)
+}
+
+func parse(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("cron: empty spec")
+	}
+
+	if strings.HasPrefix(spec, "TZ=") || strings.HasPrefix(spec, "CRON_TZ=") {
+		i := strings.IndexByte(spec, ' ')
+		if i < 0 {
+			return nil, fmt.Errorf("cron: missing fields after time zone in %q", spec)
+		}
+		name := spec[strings.IndexByte(spec, '=')+1 : i]
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("cron: time zone %q: %w", name, err)
+		}
+		loc = l
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("cron: %q: %w", spec, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("cron: %q: interval must be at least 1s", spec)
+		}
+		return everySchedule{every: d.Truncate(time.Second)}, nil
+	}
+	if d, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: expected 5 or 6 fields, found %d in %q", len(fields), spec)
+	}
+
+	var (
+		s   = &specSchedule{loc: loc}
+		err error
+	)
+	for i, f := range []struct {
+		dst *uint64
+		fd  field
+	}{
+		{&s.second, secondField},
+		{&s.minute, minuteField},
+		{&s.hour, hourField},
+		{&s.dom, domField},
+		{&s.month, monthField},
+		{&s.dow, dowField},
+	} {
+		if *f.dst, err = parseField(fields[i], f.fd); err != nil {
+			return nil, fmt.Errorf("cron: %q: %w", spec, err)
+		}
+	}
+	// Both 0 and 7 mean Sunday.
+	if s.dow&(1<<7) > 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+func parseField(expr string, fd field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		b, err := parseRange(part, fd)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+func parseRange(expr string, fd field) (uint64, error) {
+	var (
+		start, end, step uint
+		rangeAndStep     = strings.Split(expr, "/")
+		lowAndHigh       = strings.Split(rangeAndStep[0], "-")
+		extra            uint64
+		err              error
+	)
+	if len(rangeAndStep) > 2 || len(lowAndHigh) > 2 {
+		return 0, fmt.Errorf("invalid expression %q", expr)
+	}
+
+	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
+		if len(lowAndHigh) > 1 {
+			return 0, fmt.Errorf("invalid expression %q", expr)
+		}
+		start, end = fd.min, fd.max
+		extra = starBit
+	} else {
+		if start, err = parseValue(lowAndHigh[0], fd); err != nil {
+			return 0, err
+		}
+		end = start
+		if len(lowAndHigh) == 2 {
+			if end, err = parseValue(lowAndHigh[1], fd); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	step = 1
+	if len(rangeAndStep) == 2 {
+		n, err := strconv.ParseUint(rangeAndStep[1], 10, 0)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("invalid step in %q", expr)
+		}
+		step = uint(n)
+		// "N/step" means from N to the end of the range.
+		if len(lowAndHigh) == 1 && extra == 0 {
+			end = fd.max
+		}
+		if step > 1 {
+			extra = 0
+		}
+	}
+
+	if start < fd.min || end > fd.max || start > end {
+		return 0, fmt.Errorf("%q out of range [%d, %d]", expr, fd.min, fd.max)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	return bits | extra, nil
+}
+
+func parseValue(expr string, fd field) (uint, error) {
+	if v, ok := fd.names[strings.ToLower(expr)]; ok {
+		return v, nil
+	}
+	n, err := strconv.ParseUint(expr, 10, 0)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", expr)
+	}
+	return uint(n), nil
+}
+
+func (s *specSchedule) Next(t time.Time) time.Time {
+	origLoc := t.Location()
+	t = t.In(s.loc)
+
+	t = t.Add(time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+	yearLimit := t.Year() + 5
+
+	added := false
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for 1<<uint(t.Month())&s.month == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		// Handle daylight saving transitions that land away from midnight.
+		if t.Hour() != 0 {
+			if t.Hour() > 12 {
+				t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
+			} else {
+				t = t.Add(time.Duration(-t.Hour()) * time.Hour)
+			}
+		}
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.hour == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.loc)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.minute == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.second == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t.In(origLoc)
+}
+
+// dayMatches applies the cron rule that when both day-of-month and
+// day-of-week are restricted, matching either one is enough.
+func (s *specSchedule) dayMatches(t time.Time) bool {
+	domMatch := 1<<uint(t.Day())&s.dom > 0
+	dowMatch := 1<<uint(t.Weekday())&s.dow > 0
+	if s.dom&starBit > 0 || s.dow&starBit > 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -0,0 +1,327 @@
+// Package mux serves several protocols on one listener. Every accepted
+// connection is sniffed and handed to the child listener of its protocol:
+// HTTP/2 requests with a gRPC content type to GRPC, HTTP/1 requests asking
+// for "Upgrade: websocket" to WebSocket and any other HTTP request to HTTP.
+package mux
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	stdErrors "errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyper-micro/hyper/logger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+type Protocol int
+
+const (
+	HTTP Protocol = iota
+	GRPC
+	WebSocket
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case GRPC:
+		return "grpc"
+	case WebSocket:
+		return "websocket"
+	default:
+		return "http"
+	}
+}
+
+type Config struct {
+	Addr string
+	// SniffTimeout bounds how long a new connection may take to send enough
+	// bytes to identify its protocol.
+	SniffTimeout time.Duration
+}
+
+type Option struct {
+	Config
+
+	Logger logger.Logger
+}
+
+type Server struct {
+	opt      Option
+	ln       net.Listener
+	lnMu     sync.Mutex
+	children [3]*listener
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+func New(opt Option) *Server {
+	if opt.Logger == nil {
+		opt.Logger = logger.Default()
+	}
+	if opt.SniffTimeout <= 0 {
+		opt.SniffTimeout = 10 * time.Second
+	}
+
+	s := &Server{
+		opt:  opt,
+		done: make(chan struct{}),
+	}
+	for i := range s.children {
+		s.children[i] = &listener{
+			srv:   s,
+			conns: make(chan net.Conn),
+			done:  make(chan struct{}),
+		}
+	}
+	return s
+}
+
+// ChildListener returns the child listener receiving connections of protocol p,
+// to be passed to the SetListener method of the server handling it.
+// Connections of protocols whose listener is closed are dropped.
+func (s *Server) ChildListener(p Protocol) net.Listener {
+	return s.children[p]
+}
+
+func (s *Server) Listen(ctx context.Context) error {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln != nil {
+		return nil
+	}
+	ln, err := new(net.ListenConfig).Listen(ctx, "tcp", s.opt.Addr)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	return nil
+}
+
+// SetListener makes the server accept on ln, such as a listener inherited
+// from a parent process, instead of binding its own. It has no effect once
+// the server is listening.
+func (s *Server) SetListener(ln net.Listener) {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln == nil {
+		s.ln = ln
+	}
+}
+
+func (s *Server) Listener() net.Listener {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.ln
+}
+
+func (s *Server) Listening() bool {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.ln != nil
+}
+
+func (s *Server) Run() error {
+	if err := s.Listen(context.Background()); err != nil {
+		return err
+	}
+
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return nil
+			default:
+			}
+			var ne net.Error
+			if stdErrors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		go s.serve(conn)
+	}
+}
+
+func (s *Server) serve(conn net.Conn) {
+	_ = conn.SetReadDeadline(time.Now().Add(s.opt.SniffTimeout))
+	proto, buf, err := sniff(conn, conn)
+	_ = conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		s.opt.Logger.Debugf("mux: sniff %s: %v", conn.RemoteAddr(), err)
+		_ = conn.Close()
+		return
+	}
+
+	c := &sniffedConn{Conn: conn, r: io.MultiReader(bytes.NewReader(buf), conn)}
+	if !s.children[proto].deliver(c) {
+		s.opt.Logger.Debugf("mux: no %s server for %s", proto, conn.RemoteAddr())
+		_ = conn.Close()
+	}
+}
+
+func (s *Server) Shutdown() error {
+	return s.ShutdownContext(context.Background())
+}
+
+// ShutdownContext stops accepting connections. The child listeners are
+// left to the servers behind them, which close them when they shut down,
+// in whatever order, and drain their own connections.
+func (s *Server) ShutdownContext(ctx context.Context) error {
+	var err error
+	s.doneOnce.Do(func() {
+		close(s.done)
+		if ln := s.Listener(); ln != nil {
+			err = ln.Close()
+		}
+	})
+	return err
+}
+
+func (s *Server) Addr() string {
+	return s.opt.Addr
+}
+
+// sniff reads from r until the protocol is known and returns the bytes it
+// consumed. HTTP/2 clients are sent an empty SETTINGS frame on w.
+func sniff(r io.Reader, w io.Writer) (Protocol, []byte, error) {
+	var buf bytes.Buffer
+	br := bufio.NewReader(io.TeeReader(r, &buf))
+
+	method, err := br.Peek(4)
+	if err != nil {
+		return HTTP, nil, err
+	}
+	if string(method) == http2.ClientPreface[:4] {
+		preface, err := br.Peek(len(http2.ClientPreface))
+		if err != nil {
+			return HTTP, nil, err
+		}
+		if string(preface) != http2.ClientPreface {
+			return HTTP, nil, stdErrors.New("mux: invalid HTTP/2 preface")
+		}
+		_, _ = br.Discard(len(preface))
+		proto, err := sniffHTTP2(br, w)
+		return proto, buf.Bytes(), err
+	}
+
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return HTTP, nil, err
+	}
+	if isWebSocket(req.Header) {
+		return WebSocket, buf.Bytes(), nil
+	}
+	return HTTP, buf.Bytes(), nil
+}
+
+// sniffHTTP2 reads frames up to the first HEADERS frame and checks its
+// content type. Clients such as grpc-go wait for the server's SETTINGS
+// before sending any request, so an empty one is written as soon as the
+// client's arrives; the server behind the mux sends its own afterwards.
+func sniffHTTP2(r io.Reader, w io.Writer) (Protocol, error) {
+	framer := http2.NewFramer(w, r)
+	proto := HTTP
+	decoder := hpack.NewDecoder(4096, func(f hpack.HeaderField) {
+		if f.Name == "content-type" && strings.HasPrefix(f.Value, "application/grpc") {
+			proto = GRPC
+		}
+	})
+	for {
+		frame, err := framer.ReadFrame()
+		if err != nil {
+			return HTTP, err
+		}
+		switch f := frame.(type) {
+		case *http2.SettingsFrame:
+			if !f.IsAck() {
+				if err := framer.WriteSettings(); err != nil {
+					return HTTP, err
+				}
+			}
+		case *http2.HeadersFrame:
+			if _, err := decoder.Write(f.HeaderBlockFragment()); err != nil {
+				return HTTP, err
+			}
+			if f.HeadersEnded() {
+				return proto, nil
+			}
+		case *http2.ContinuationFrame:
+			if _, err := decoder.Write(f.HeaderBlockFragment()); err != nil {
+				return HTTP, err
+			}
+			if f.HeadersEnded() {
+				return proto, nil
+			}
+		}
+	}
+}
+
+func isWebSocket(h http.Header) bool {
+	for _, v := range h.Values("Upgrade") {
+		for _, token := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(token), "websocket") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type sniffedConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *sniffedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+type listener struct {
+	srv       *Server
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *listener) deliver(conn net.Conn) bool {
+	select {
+	case l.conns <- conn:
+		return true
+	case <-l.done:
+		return false
+	}
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *listener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
+
+func (l *listener) Addr() net.Addr {
+	if ln := l.srv.Listener(); ln != nil {
+		return ln.Addr()
+	}
+	return &net.TCPAddr{}
+}
@@ -0,0 +1,153 @@
+package mux
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestServer_Routing(t *testing.T) {
+	s := New(Option{Config: Config{Addr: "127.0.0.1:0"}})
+	require.NoError(t, s.Listen(context.Background()))
+	go func() {
+		_ = s.Run()
+	}()
+	defer s.Shutdown()
+
+	httpSrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "http")
+	})}
+	go httpSrv.Serve(s.ChildListener(HTTP))
+	defer httpSrv.Close()
+
+	grpcSrv := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcSrv, health.NewServer())
+	go grpcSrv.Serve(s.ChildListener(GRPC))
+	defer grpcSrv.Stop()
+
+	upgrader := websocket.Upgrader{}
+	wsSrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("websocket"))
+	})}
+	go wsSrv.Serve(s.ChildListener(WebSocket))
+	defer wsSrv.Close()
+
+	addr := s.Listener().Addr().String()
+
+	resp, err := http.Get("http://" + addr + "/")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "http", string(body))
+
+	cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+	hr, err := healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, hr.Status)
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/", nil)
+	require.NoError(t, err)
+	defer ws.Close()
+	_, msg, err := ws.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "websocket", string(msg))
+}
+
+func TestServer_ShutdownOrder(t *testing.T) {
+	for _, muxFirst := range []bool{true, false} {
+		s := New(Option{Config: Config{Addr: "127.0.0.1:0"}})
+		require.NoError(t, s.Listen(context.Background()))
+		muxErr := make(chan error, 1)
+		go func() {
+			muxErr <- s.Run()
+		}()
+
+		httpSrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+		httpErr := make(chan error, 1)
+		go func() {
+			httpErr <- httpSrv.Serve(s.ChildListener(HTTP))
+		}()
+		grpcSrv := grpc.NewServer()
+		healthpb.RegisterHealthServer(grpcSrv, health.NewServer())
+		grpcErr := make(chan error, 1)
+		go func() {
+			grpcErr <- grpcSrv.Serve(s.ChildListener(GRPC))
+		}()
+
+		addr := s.Listener().Addr().String()
+		resp, err := http.Get("http://" + addr + "/")
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		_, err = healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		_ = cc.Close()
+
+		if muxFirst {
+			require.NoError(t, s.Shutdown())
+			// The servers keep their listeners until their own shutdown.
+			select {
+			case err := <-httpErr:
+				t.Fatalf("http server stopped with the mux: %v", err)
+			case err := <-grpcErr:
+				t.Fatalf("grpc server stopped with the mux: %v", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+		require.NoError(t, httpSrv.Shutdown(context.Background()))
+		grpcSrv.GracefulStop()
+		if !muxFirst {
+			require.NoError(t, s.Shutdown())
+		}
+
+		assert.ErrorIs(t, <-httpErr, http.ErrServerClosed, "mux first: %v", muxFirst)
+		assert.NoError(t, <-grpcErr, "mux first: %v", muxFirst)
+		assert.NoError(t, <-muxErr, "mux first: %v", muxFirst)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Protocol
+	}{
+		{"short request", "GET / HTTP/1.0\r\n\r\n", HTTP},
+		{"websocket", "GET /ws HTTP/1.1\r\nHost: x\r\nConnection: Upgrade\r\nUpgrade: WebSocket\r\n\r\n", WebSocket},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proto, buf, err := sniff(pendingReader(tt.input), io.Discard)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, proto)
+			assert.Equal(t, tt.input, string(buf))
+		})
+	}
+}
+
+// pendingReader returns s without EOF, like a client waiting for a response.
+func pendingReader(s string) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(pw, s)
+	}()
+	return pr
+}
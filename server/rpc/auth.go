@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/hyper-micro/hyper/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type AuthOption struct {
+	Authenticator auth.Authenticator
+	// Public lists full method names, such as "/pkg.Service/Method",
+	// served without credentials. A trailing "*" matches any method of a
+	// service. The health service is always public.
+	Public []string
+	// Roles requires the caller to have one of the roles, by full method
+	// name.
+	Roles map[string][]string
+	// Scopes requires the caller to have every one of the scopes, by full
+	// method name.
+	Scopes map[string][]string
+}
+
+// healthService prefixes the methods of the grpc.health.v1 service, which
+// probes call without credentials.
+const healthService = "/grpc.health.v1.Health/"
+
+type authenticator struct {
+	opt AuthOption
+}
+
+func newAuthenticator(opt AuthOption) *authenticator {
+	opt.Public = append(slices.Clone(opt.Public), healthService+"*")
+	return &authenticator{opt: opt}
+}
+
+// mdHeader reads the first value of a metadata key.
+type mdHeader metadata.MD
+
+func (h mdHeader) Get(key string) string {
+	if vals := metadata.MD(h).Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+func (a *authenticator) public(fullMethod string) bool {
+	for _, m := range a.opt.Public {
+		if prefix, ok := strings.CutSuffix(m, "*"); ok {
+			if strings.HasPrefix(fullMethod, prefix) {
+				return true
+			}
+		} else if m == fullMethod {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate returns ctx carrying the caller's principal, failing with
+// codes.Unauthenticated or codes.PermissionDenied.
+func (a *authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if a.public(fullMethod) {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	p, err := a.opt.Authenticator.Authenticate(ctx, mdHeader(md))
+	if err != nil {
+		if errors.Is(err, auth.ErrNoCredentials) || errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "authenticate: %v", err)
+	}
+	if roles, ok := a.opt.Roles[fullMethod]; ok && !p.HasAnyRole(roles...) {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	if scopes, ok := a.opt.Scopes[fullMethod]; ok && !p.HasScopes(scopes...) {
+		return nil, status.Error(codes.PermissionDenied, "permission denied")
+	}
+	return auth.ContextWithPrincipal(ctx, p), nil
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
+
+// AuthUnaryInterceptor authenticates unary calls, for servers not built
+// with Option.Auth. Handlers read the caller with auth.PrincipalFromContext.
+func AuthUnaryInterceptor(opt AuthOption) grpc.UnaryServerInterceptor {
+	return newAuthenticator(opt).unaryInterceptor
+}
+
+func AuthStreamInterceptor(opt AuthOption) grpc.StreamServerInterceptor {
+	return newAuthenticator(opt).streamInterceptor
+}
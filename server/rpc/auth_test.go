@@ -0,0 +1,110 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyper-micro/hyper/auth"
+	"github.com/hyper-micro/hyper/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuth(t *testing.T) {
+	a := newAuthenticator(AuthOption{
+		Authenticator: auth.APIKey("x-api-key", auth.StaticAPIKeys(map[string]*auth.Principal{
+			"k-user":  {Subject: "user", Roles: []string{"user"}},
+			"k-admin": {Subject: "admin", Roles: []string{"admin"}, Scopes: []string{"write"}},
+		})),
+		Public: []string{"/pkg.Greeter/Hello"},
+		Roles:  map[string][]string{"/pkg.Greeter/Delete": {"admin"}},
+		Scopes: map[string][]string{"/pkg.Greeter/Delete": {"write"}},
+	})
+	call := func(method, key string) (any, error) {
+		ctx := context.Background()
+		if key != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", key))
+		}
+		return a.unaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			if p := auth.PrincipalFromContext(ctx); p != nil {
+				return p.Subject, nil
+			}
+			return "anonymous", nil
+		})
+	}
+
+	resp, err := call("/pkg.Greeter/Hello", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "anonymous", resp)
+	_, err = call("/grpc.health.v1.Health/Check", "")
+	assert.NoError(t, err)
+
+	_, err = call("/pkg.Greeter/Bye", "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = call("/pkg.Greeter/Bye", "wrong")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	resp, err = call("/pkg.Greeter/Bye", "k-user")
+	assert.NoError(t, err)
+	assert.Equal(t, "user", resp)
+
+	_, err = call("/pkg.Greeter/Delete", "k-user")
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	resp, err = call("/pkg.Greeter/Delete", "k-admin")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", resp)
+}
+
+func TestAuth_BeforeRateLimit(t *testing.T) {
+	srv := New(Option{
+		Config: Config{Addr: "127.0.0.1:0", MaxRecvMsgSize: 1 << 20, MaxSendMsgSize: 1 << 20},
+		ServiceOpts: []grpc.ServerOption{grpc.UnknownServiceHandler(func(_ any, ss grpc.ServerStream) error {
+			var req healthpb.HealthCheckRequest
+			if err := ss.RecvMsg(&req); err != nil {
+				return err
+			}
+			return ss.SendMsg(&healthpb.HealthCheckResponse{})
+		})},
+		HealthCheck: func(context.Context) error { return nil },
+		Auth: &AuthOption{
+			Authenticator: auth.APIKey("x-api-key", auth.StaticAPIKeys(map[string]*auth.Principal{
+				"k-user": {Subject: "user"},
+			})),
+		},
+		RateLimit: &RateLimitOption{
+			Limiter: ratelimit.New(ratelimit.Option{Rate: ratelimit.PerMinute(1)}),
+			KeyFunc: func(ctx context.Context, fullMethod string) string {
+				if p := auth.PrincipalFromContext(ctx); p != nil {
+					return p.Subject
+				}
+				return KeyByPeer(ctx, fullMethod)
+			},
+		},
+	})
+	require.NoError(t, srv.Listen(context.Background()))
+	go func() {
+		_ = srv.Run()
+	}()
+	defer srv.Shutdown()
+
+	cc, err := grpc.NewClient(srv.Listener().Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer cc.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "k-user")
+	call := func() error {
+		return cc.Invoke(ctx, "/pkg.Greeter/Hello", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	}
+	require.NoError(t, call())
+	assert.Equal(t, codes.ResourceExhausted, status.Code(call()))
+
+	for i := 0; i < 3; i++ {
+		_, err := healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,52 @@
+package rpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hyper-micro/hyper/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+type serverMetrics struct {
+	handled  *metrics.Counter
+	duration *metrics.Histogram
+}
+
+func newServerMetrics(r *metrics.Registry) *serverMetrics {
+	return &serverMetrics{
+		handled:  r.NewCounter("grpc_server_handled_total", "gRPC calls completed, by status code.", "service", "method", "code"),
+		duration: r.NewHistogram("grpc_server_handling_seconds", "gRPC call latency.", metrics.DefBuckets, "service", "method"),
+	}
+}
+
+func (m *serverMetrics) observe(fullMethod string, start time.Time, err error) {
+	service, method := splitMethod(fullMethod)
+	m.handled.Inc(service, method, status.Code(err).String())
+	m.duration.Observe(time.Since(start).Seconds(), service, method)
+}
+
+func (m *serverMetrics) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observe(info.FullMethod, start, err)
+	return resp, err
+}
+
+func (m *serverMetrics) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observe(info.FullMethod, start, err)
+	return err
+}
+
+// splitMethod splits "/package.Service/Method" into service and method.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
@@ -0,0 +1,141 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyper-micro/hyper/auth"
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimitKeyFunc returns the key a call is limited by. An empty key
+// skips the limit.
+type RateLimitKeyFunc func(ctx context.Context, fullMethod string) string
+
+// KeyByPeer limits each client address.
+func KeyByPeer(ctx context.Context, _ string) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// KeyByPrincipal limits each principal authenticated by Option.Auth or
+// AuthUnaryInterceptor, which must run first. Anonymous calls are not
+// limited by it.
+func KeyByPrincipal(ctx context.Context, _ string) string {
+	p := auth.PrincipalFromContext(ctx)
+	if p == nil {
+		return ""
+	}
+	return p.Method + ":" + p.Subject
+}
+
+type RateLimitOption struct {
+	// Limiter applies to methods without their own in Methods.
+	Limiter *ratelimit.Limiter
+	// Methods holds limiters of individual methods, by full method name
+	// such as "/pkg.Service/Method".
+	Methods map[string]*ratelimit.Limiter
+	// KeyFunc defaults to KeyByPeer.
+	KeyFunc RateLimitKeyFunc
+	// Logger receives store errors, on which calls are let through. It
+	// defaults to logger.Default().
+	Logger logger.Logger
+}
+
+type rateLimiter struct {
+	opt RateLimitOption
+}
+
+func newRateLimiter(opt RateLimitOption) *rateLimiter {
+	if opt.KeyFunc == nil {
+		opt.KeyFunc = KeyByPeer
+	}
+	if opt.Logger == nil {
+		opt.Logger = logger.Default()
+	}
+	return &rateLimiter{opt: opt}
+}
+
+// allow fails calls over the limit with codes.ResourceExhausted, sending
+// the limit in ratelimit-* and retry-after header metadata.
+func (r *rateLimiter) allow(ctx context.Context, fullMethod string, setHeader func(metadata.MD) error) error {
+	if strings.HasPrefix(fullMethod, healthService) {
+		return nil
+	}
+	limiter := r.opt.Methods[fullMethod]
+	key := r.opt.KeyFunc(ctx, fullMethod)
+	if limiter == nil {
+		limiter = r.opt.Limiter
+	} else if key != "" {
+		key = fullMethod + ":" + key
+	}
+	if limiter == nil || key == "" {
+		return nil
+	}
+
+	res, err := limiter.Allow(ctx, key)
+	if err != nil {
+		r.opt.Logger.Errorf("rpc: rate limit %q: %v", key, err)
+		return nil
+	}
+	md := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(res.Limit),
+		"ratelimit-remaining", strconv.Itoa(res.Remaining),
+		"ratelimit-reset", seconds(res.Reset),
+	)
+	if res.Allowed {
+		_ = setHeader(md)
+		return nil
+	}
+	md.Set("retry-after", seconds(res.RetryAfter))
+	_ = setHeader(md)
+	return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry after %s", res.RetryAfter)
+}
+
+func (r *rateLimiter) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	err := r.allow(ctx, info.FullMethod, func(md metadata.MD) error {
+		return grpc.SetHeader(ctx, md)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (r *rateLimiter) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := r.allow(ss.Context(), info.FullMethod, ss.SetHeader); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// RateLimitUnaryInterceptor limits unary calls, for servers not built
+// with Option.RateLimit.
+func RateLimitUnaryInterceptor(opt RateLimitOption) grpc.UnaryServerInterceptor {
+	return newRateLimiter(opt).unaryInterceptor
+}
+
+func RateLimitStreamInterceptor(opt RateLimitOption) grpc.StreamServerInterceptor {
+	return newRateLimiter(opt).streamInterceptor
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,54 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/hyper-micro/hyper/auth"
+	"github.com/hyper-micro/hyper/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimit(t *testing.T) {
+	rl := newRateLimiter(RateLimitOption{
+		Limiter: ratelimit.New(ratelimit.Option{Rate: ratelimit.PerMinute(2)}),
+		Methods: map[string]*ratelimit.Limiter{
+			"/pkg.Greeter/Expensive": ratelimit.New(ratelimit.Option{Rate: ratelimit.PerMinute(1)}),
+		},
+	})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+	var header metadata.MD
+	setHeader := func(md metadata.MD) error {
+		header = md
+		return nil
+	}
+
+	assert.NoError(t, rl.allow(ctx, "/pkg.Greeter/Hello", setHeader))
+	assert.Equal(t, []string{"1"}, header.Get("ratelimit-remaining"))
+	assert.NoError(t, rl.allow(ctx, "/pkg.Greeter/Bye", setHeader))
+	err := rl.allow(ctx, "/pkg.Greeter/Hello", setHeader)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"30"}, header.Get("retry-after"))
+
+	assert.NoError(t, rl.allow(ctx, "/pkg.Greeter/Expensive", setHeader))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(rl.allow(ctx, "/pkg.Greeter/Expensive", setHeader)))
+
+	other := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5000}})
+	resp, err := rl.unaryInterceptor(other, nil, &grpc.UnaryServerInfo{FullMethod: "/pkg.Greeter/Hello"}, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestKeyByPrincipal(t *testing.T) {
+	ctx := auth.ContextWithPrincipal(context.Background(), &auth.Principal{Subject: "alice", Method: "apikey"})
+	assert.Equal(t, "apikey:alice", KeyByPrincipal(ctx, "/pkg.Greeter/Hello"))
+	assert.Empty(t, KeyByPrincipal(context.Background(), "/pkg.Greeter/Hello"))
+}
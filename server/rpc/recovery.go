@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/hyper-micro/hyper/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PanicHandler reports a panic recovered from a gRPC method, such as to an
+// error tracker. The call fails with codes.Internal after it returns.
+type PanicHandler func(ctx context.Context, fullMethod string, p any, stack []byte)
+
+type recovery struct {
+	srv *Server
+}
+
+func (r *recovery) handle(ctx context.Context, fullMethod string, p any) error {
+	stack := debug.Stack()
+	r.srv.logger().Errorf("rpc: panic serving %s: %v\n%s", fullMethod, p, stack)
+	if r.srv.opt.PanicHandler != nil {
+		r.srv.opt.PanicHandler(ctx, fullMethod, p, stack)
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+func (r *recovery) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			resp, err = nil, r.handle(ctx, info.FullMethod, p)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func (r *recovery) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = r.handle(ss.Context(), info.FullMethod, p)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func (s *Server) logger() logger.Logger {
+	if s.opt.Logger != nil {
+		return s.opt.Logger
+	}
+	return logger.Default()
+}
@@ -0,0 +1,37 @@
+package rpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyper-micro/hyper/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecovery(t *testing.T) {
+	var reported string
+	srv := New(Option{
+		Logger: loggertest.New(),
+		PanicHandler: func(ctx context.Context, fullMethod string, p any, stack []byte) {
+			reported = fullMethod
+		},
+	})
+	rec := &recovery{srv: srv}
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Greeter/Hello"}
+
+	resp, err := rec.unaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	assert.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "/pkg.Greeter/Hello", reported)
+
+	resp, err = rec.unaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
@@ -1,9 +1,18 @@
 package rpc
 
 import (
+	"context"
 	"net"
+	"sync"
+	"time"
+
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/metrics"
+	"github.com/hyper-micro/hyper/tracing"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -14,18 +23,41 @@ type Config struct {
 	MaxRecvMsgSize int
 	MaxSendMsgSize int
 	Reflection     bool
+	HealthInterval time.Duration
 }
 
 type Option struct {
 	Config
 
 	ServiceOpts []grpc.ServerOption
+	// HealthCheck, when set, enables the grpc.health.v1 service. Every
+	// registered service reports SERVING while it returns nil.
+	HealthCheck func(ctx context.Context) error
+	// Metrics, when set, records call count and latency per method.
+	Metrics *metrics.Registry
+	// Tracer, when set, starts a server span for every call, continuing the
+	// trace of the caller's traceparent metadata.
+	Tracer *tracing.Tracer
+	// Logger receives recovered panics and defaults to logger.Default().
+	Logger       logger.Logger
+	PanicHandler PanicHandler
+	// RateLimit, when set, fails calls over the limit with
+	// codes.ResourceExhausted. The health service is not limited.
+	RateLimit *RateLimitOption
+	// Auth, when set, authenticates calls other than its public methods
+	// and guards methods by role and scope.
+	Auth *AuthOption
 }
 
 type Server struct {
 	opt      Option
 	srv      *grpc.Server
 	handlers []HandlerFn
+	ln       net.Listener
+	lnMu     sync.Mutex
+	health   *health.Server
+	stopped  chan struct{}
+	stopOnce sync.Once
 }
 
 type HandlerFn func(srv *grpc.Server)
@@ -39,35 +71,190 @@ func New(opt Option) *Server {
 		grpc.MaxSendMsgSize(opt.MaxSendMsgSize),
 	}
 
-	srvOpts = append(srvOpts, opt.ServiceOpts...)
+	if opt.Metrics != nil {
+		m := newServerMetrics(opt.Metrics)
+		srvOpts = append(srvOpts,
+			grpc.ChainUnaryInterceptor(m.unaryInterceptor),
+			grpc.ChainStreamInterceptor(m.streamInterceptor),
+		)
+	}
+
+	if opt.Tracer != nil {
+		t := &serverTracing{tracer: opt.Tracer}
+		srvOpts = append(srvOpts,
+			grpc.ChainUnaryInterceptor(t.unaryInterceptor),
+			grpc.ChainStreamInterceptor(t.streamInterceptor),
+		)
+	}
 
 	srv := &Server{
-		opt: opt,
-		srv: grpc.NewServer(srvOpts...),
+		opt:     opt,
+		stopped: make(chan struct{}),
+	}
+
+	// Recovery runs inside metrics and tracing, so they see codes.Internal.
+	rec := &recovery{srv: srv}
+	srvOpts = append(srvOpts,
+		grpc.ChainUnaryInterceptor(rec.unaryInterceptor),
+		grpc.ChainStreamInterceptor(rec.streamInterceptor),
+	)
+
+	// Auth runs before rate limiting, so key funcs can limit by principal.
+	if opt.Auth != nil {
+		a := newAuthenticator(*opt.Auth)
+		srvOpts = append(srvOpts,
+			grpc.ChainUnaryInterceptor(a.unaryInterceptor),
+			grpc.ChainStreamInterceptor(a.streamInterceptor),
+		)
+	}
+
+	if opt.RateLimit != nil {
+		rl := newRateLimiter(*opt.RateLimit)
+		srvOpts = append(srvOpts,
+			grpc.ChainUnaryInterceptor(rl.unaryInterceptor),
+			grpc.ChainStreamInterceptor(rl.streamInterceptor),
+		)
+	}
+
+	srvOpts = append(srvOpts, opt.ServiceOpts...)
+	srv.srv = grpc.NewServer(srvOpts...)
+
+	if opt.HealthCheck != nil {
+		srv.health = health.NewServer()
+		healthpb.RegisterHealthServer(srv.srv, srv.health)
 	}
 
 	return srv
 }
 
-func (s *Server) Run() error {
-	l, err := net.Listen("tcp", s.opt.Addr)
+func (s *Server) Listen(ctx context.Context) error {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln != nil {
+		return nil
+	}
+	ln, err := new(net.ListenConfig).Listen(ctx, "tcp", s.opt.Addr)
 	if err != nil {
 		return err
 	}
+	s.ln = ln
+	return nil
+}
+
+// SetListener makes the server serve on ln, such as a listener inherited
+// from a parent process, instead of binding its own. It has no effect once
+// the server is listening.
+func (s *Server) SetListener(ln net.Listener) {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln == nil {
+		s.ln = ln
+	}
+}
+
+func (s *Server) Listener() net.Listener {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.ln
+}
+
+func (s *Server) Listening() bool {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.ln != nil
+}
+
+func (s *Server) Run() error {
+	if err := s.Listen(context.Background()); err != nil {
+		return err
+	}
 
 	for _, h := range s.handlers {
 		h(s.srv)
 	}
 
-	return s.srv.Serve(l)
+	if s.health != nil {
+		go s.watchHealth()
+	}
+
+	return s.srv.Serve(s.ln)
+}
+
+func (s *Server) watchHealth() {
+	interval := s.opt.HealthInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.updateHealth()
+		select {
+		case <-s.stopped:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) updateHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if err := s.opt.HealthCheck(ctx); err != nil {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	s.health.SetServingStatus("", status)
+	for name := range s.srv.GetServiceInfo() {
+		s.health.SetServingStatus(name, status)
+	}
+}
+
+func (s *Server) stopHealth() {
+	s.stopOnce.Do(func() {
+		close(s.stopped)
+		if s.health != nil {
+			s.health.Shutdown()
+		}
+	})
 }
 
 func (s *Server) Shutdown() error {
+	s.stopHealth()
 	s.srv.GracefulStop()
 
 	return nil
 }
 
+// ShutdownContext stops the server gracefully, forcing it closed when ctx
+// expires before pending RPCs finish.
+func (s *Server) ShutdownContext(ctx context.Context) error {
+	s.stopHealth()
+
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Services returns the registered services by name. Handlers are applied
+// in Run, so the result is empty before the server runs.
+func (s *Server) Services() map[string]grpc.ServiceInfo {
+	return s.srv.GetServiceInfo()
+}
+
 func (s *Server) Handler(handler HandlerFn) {
 	h := func(srv *grpc.Server) {
 		if s.opt.Reflection {
@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/hyper-micro/hyper/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+type serverTracing struct {
+	tracer *tracing.Tracer
+}
+
+func (t *serverTracing) start(ctx context.Context, fullMethod string) (context.Context, *tracing.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = tracing.Extract(ctx, metadataCarrier(md))
+	}
+	service, method := splitMethod(fullMethod)
+	ctx, span := t.tracer.Start(ctx, service+"/"+method, tracing.WithKind(tracing.SpanKindServer))
+	span.SetAttribute("rpc.system", "grpc")
+	span.SetAttribute("rpc.service", service)
+	span.SetAttribute("rpc.method", method)
+	return ctx, span
+}
+
+func endSpan(span *tracing.Span, err error) {
+	code := status.Code(err)
+	span.SetAttribute("rpc.grpc.status_code", int(code))
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+func (t *serverTracing) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, span := t.start(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endSpan(span, err)
+	return resp, err
+}
+
+func (t *serverTracing) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := t.start(ss.Context(), info.FullMethod)
+	err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+	endSpan(span, err)
+	return err
+}
+
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor traces outgoing calls and propagates their trace
+// context to the server. A nil tracer uses tracing.Default().
+func UnaryClientInterceptor(tracer *tracing.Tracer) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, fullMethod string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := startClientSpan(ctx, tracer, fullMethod)
+		err := invoker(ctx, fullMethod, req, reply, cc, opts...)
+		endSpan(span, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor is like UnaryClientInterceptor for streams. The
+// span ends when the stream is established.
+func StreamClientInterceptor(tracer *tracing.Tracer) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, fullMethod string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := startClientSpan(ctx, tracer, fullMethod)
+		cs, err := streamer(ctx, desc, cc, fullMethod, opts...)
+		endSpan(span, err)
+		return cs, err
+	}
+}
+
+func startClientSpan(ctx context.Context, tracer *tracing.Tracer, fullMethod string) (context.Context, *tracing.Span) {
+	if tracer == nil {
+		tracer = tracing.Default()
+	}
+	service, method := splitMethod(fullMethod)
+	ctx, span := tracer.Start(ctx, service+"/"+method, tracing.WithKind(tracing.SpanKindClient))
+	span.SetAttribute("rpc.system", "grpc")
+	span.SetAttribute("rpc.service", service)
+	span.SetAttribute("rpc.method", method)
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	tracing.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
@@ -0,0 +1,177 @@
+package web
+
+import (
+	"encoding/xml"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/hyper-micro/hyper/internal/json"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	MIMEJSON              = "application/json"
+	MIMEXML               = "application/xml"
+	MIMEXML2              = "text/xml"
+	MIMEPOSTForm          = "application/x-www-form-urlencoded"
+	MIMEMultipartPOSTForm = "multipart/form-data"
+	MIMEMsgpack           = "application/msgpack"
+	MIMEMsgpack2          = "application/x-msgpack"
+)
+
+// FieldError describes a field that failed conversion or validation.
+type FieldError struct {
+	// Field is the path of the field named after its binding tags, such as
+	// "address.city". Form and query values that fail conversion are named
+	// by their key alone, as nested structs share the flat set of keys.
+	Field string `json:"field"`
+	// Tag is the failed validation tag, or "type" when the value could not
+	// be converted to the field's type.
+	Tag     string `json:"tag"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// BindingError is returned when a request cannot be bound. It is answered
+// 400 by DefaultErrorHandler.
+type BindingError struct {
+	// Err is the decoding error, if the body could not be decoded, or the
+	// validator.ValidationErrors that Fields describe.
+	Err    error
+	Fields []FieldError
+}
+
+func (e *BindingError) Error() string {
+	if len(e.Fields) == 0 && e.Err != nil {
+		return e.Err.Error()
+	}
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *BindingError) Unwrap() error {
+	return e.Err
+}
+
+func decodeBody(d any, decode func(r io.Reader, d any) error, body io.Reader, locale string) error {
+	if err := decode(body, d); err != nil {
+		return &BindingError{Err: err}
+	}
+	return validateStruct(d, locale)
+}
+
+func decodeJSON(r io.Reader, d any) error {
+	return json.NewDecoder(r).Decode(d)
+}
+
+func decodeXML(r io.Reader, d any) error {
+	return xml.NewDecoder(r).Decode(d)
+}
+
+func decodeMsgpack(r io.Reader, d any) error {
+	return msgpack.NewDecoder(r).Decode(d)
+}
+
+/// Binding
+
+func (c *ctx) ShouldBind(d any) error {
+	if c.r.Method == http.MethodGet || c.r.Method == http.MethodHead {
+		return c.ShouldBindQuery(d)
+	}
+
+	contentType, _, _ := mime.ParseMediaType(c.r.Header.Get("Content-Type"))
+	switch contentType {
+	case "", MIMEJSON:
+		return decodeBody(d, decodeJSON, c.r.Body, c.Locale())
+	case MIMEXML, MIMEXML2:
+		return decodeBody(d, decodeXML, c.r.Body, c.Locale())
+	case MIMEMsgpack, MIMEMsgpack2:
+		return decodeBody(d, decodeMsgpack, c.r.Body, c.Locale())
+	case MIMEPOSTForm, MIMEMultipartPOSTForm:
+		return c.shouldBindForm(d)
+	}
+	return NewHTTPError(http.StatusUnsupportedMediaType)
+}
+
+func (c *ctx) shouldBindForm(d any) error {
+	if err := c.r.ParseMultipartForm(c.srv.MaxMultipartMemory); err != nil && err != http.ErrNotMultipart {
+		return &BindingError{Err: err}
+	}
+	var files map[string][]*multipart.FileHeader
+	if c.r.MultipartForm != nil {
+		files = c.r.MultipartForm.File
+	}
+	err := bindValues(d, "form", func(name string) ([]string, bool) {
+		vals, ok := c.r.Form[name]
+		return vals, ok
+	}, files)
+	if err != nil {
+		return err
+	}
+	return validateStruct(d, c.Locale())
+}
+
+func (c *ctx) ShouldBindQuery(d any) error {
+	c.initQueryCache()
+	err := bindValues(d, "query", func(name string) ([]string, bool) {
+		vals, ok := c.queryCache[name]
+		return vals, ok
+	}, nil)
+	if err != nil {
+		return err
+	}
+	return validateStruct(d, c.Locale())
+}
+
+func (c *ctx) ShouldBindUri(d any) error {
+	vars := mux.Vars(c.r)
+	err := bindValues(d, "uri", func(name string) ([]string, bool) {
+		v, ok := vars[name]
+		return []string{v}, ok
+	}, nil)
+	if err != nil {
+		return err
+	}
+	return validateStruct(d, c.Locale())
+}
+
+func (c *ctx) ShouldBindHeader(d any) error {
+	err := bindValues(d, "header", func(name string) ([]string, bool) {
+		vals, ok := c.r.Header[textproto.CanonicalMIMEHeaderKey(name)]
+		return vals, ok
+	}, nil)
+	if err != nil {
+		return err
+	}
+	return validateStruct(d, c.Locale())
+}
+
+// bind passes a binding error to Error.
+func (c *ctx) bind(err error) error {
+	c.Error(err)
+	return err
+}
+
+func (c *ctx) Bind(d any) error {
+	return c.bind(c.ShouldBind(d))
+}
+
+func (c *ctx) BindQuery(d any) error {
+	return c.bind(c.ShouldBindQuery(d))
+}
+
+func (c *ctx) BindUri(d any) error {
+	return c.bind(c.ShouldBindUri(d))
+}
+
+func (c *ctx) BindHeader(d any) error {
+	return c.bind(c.ShouldBindHeader(d))
+}
@@ -0,0 +1,160 @@
+package web
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type userForm struct {
+	Name    string                `json:"name" xml:"name" form:"name" msgpack:"name" validate:"required"`
+	Age     int                   `json:"age" xml:"age" form:"age" msgpack:"age" validate:"gte=18"`
+	Tags    []string              `json:"tags" xml:"tags" form:"tags" msgpack:"tags"`
+	Avatar  *multipart.FileHeader `json:"-" xml:"-" form:"avatar" msgpack:"-"`
+	Comment string                `json:"-" xml:"-" form:"-" msgpack:"-"`
+}
+
+func bindRequest(t *testing.T, req *http.Request, bind func(ctx Ctx) error) (int, string) {
+	t.Helper()
+	srv := New(Option{})
+	srv.Any("/users/{id}", func(ctx Ctx) {
+		if err := bind(ctx); err == nil {
+			_ = ctx.String("ok")
+		}
+	})
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func TestCtx_Bind(t *testing.T) {
+	var got userForm
+	bind := func(ctx Ctx) error {
+		got = userForm{}
+		return ctx.Bind(&got)
+	}
+	want := userForm{Name: "ann", Age: 30, Tags: []string{"a", "b"}}
+
+	req := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`{"name":"ann","age":30,"tags":["a","b"]}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	code, _ := bindRequest(t, req, bind)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, want, got)
+
+	req = httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`<user><name>ann</name><age>30</age><tags>a</tags><tags>b</tags></user>`))
+	req.Header.Set("Content-Type", "application/xml")
+	bindRequest(t, req, bind)
+	assert.Equal(t, want, got)
+
+	b, err := msgpack.Marshal(want)
+	require.NoError(t, err)
+	req = httptest.NewRequest(http.MethodPost, "/users/1", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/msgpack")
+	bindRequest(t, req, bind)
+	assert.Equal(t, want, got)
+
+	req = httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader("name=ann&age=30&tags=a&tags=b&Comment=x"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	bindRequest(t, req, bind)
+	assert.Equal(t, want, got)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("name", "ann")
+	_ = mw.WriteField("age", "30")
+	fw, _ := mw.CreateFormFile("avatar", "me.png")
+	_, _ = fw.Write([]byte("png"))
+	_ = mw.Close()
+	req = httptest.NewRequest(http.MethodPut, "/users/1", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	bindRequest(t, req, bind)
+	require.NotNil(t, got.Avatar)
+	assert.Equal(t, "me.png", got.Avatar.Filename)
+	assert.Equal(t, "ann", got.Name)
+
+	req = httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`{"age":3}`))
+	code, resp := bindRequest(t, req, bind)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.JSONEq(t, `{"code":400,"message":"name is a required field; age must be 18 or greater","fields":[
+		{"field":"name","tag":"required","message":"name is a required field"},
+		{"field":"age","tag":"gte","param":"18","message":"age must be 18 or greater"}]}`, resp)
+
+	var bindErr error
+	req = httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`{"age":3}`))
+	bindRequest(t, req, func(ctx Ctx) error {
+		bindErr = ctx.ShouldBind(&userForm{})
+		return bindErr
+	})
+	var ves validator.ValidationErrors
+	require.ErrorAs(t, bindErr, &ves)
+	assert.Len(t, ves, 2)
+
+	req = httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`{`))
+	code, _ = bindRequest(t, req, bind)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	req = httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`name: ann`))
+	req.Header.Set("Content-Type", "application/yaml")
+	code, _ = bindRequest(t, req, bind)
+	assert.Equal(t, http.StatusUnsupportedMediaType, code)
+}
+
+func TestCtx_BindValues(t *testing.T) {
+	type page struct {
+		Page  int `query:"page,default=1"`
+		Limit int `query:"limit,default=20" validate:"lte=100"`
+	}
+	type params struct {
+		page
+		ID      uint64        `uri:"id"`
+		Since   time.Time     `query:"since"`
+		Timeout time.Duration `query:"timeout"`
+		Sort    *string       `query:"sort"`
+		Token   string        `header:"x-token"`
+	}
+
+	var got params
+	bind := func(ctx Ctx) error {
+		got = params{}
+		if err := ctx.BindUri(&got); err != nil {
+			return err
+		}
+		if err := ctx.BindHeader(&got); err != nil {
+			return err
+		}
+		return ctx.BindQuery(&got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7?since=2024-01-02T03:04:05Z&timeout=1s&sort=name", nil)
+	req.Header.Set("X-Token", "secret")
+	code, _ := bindRequest(t, req, bind)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, uint64(7), got.ID)
+	assert.Equal(t, 1, got.Page)
+	assert.Equal(t, 20, got.Limit)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), got.Since)
+	assert.Equal(t, time.Second, got.Timeout)
+	require.NotNil(t, got.Sort)
+	assert.Equal(t, "name", *got.Sort)
+	assert.Equal(t, "secret", got.Token)
+
+	req = httptest.NewRequest(http.MethodGet, "/users/x", nil)
+	code, resp := bindRequest(t, req, bind)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, resp, `{"field":"id","tag":"type","param":"uint64","message":"id must be a valid uint64"}`)
+
+	req = httptest.NewRequest(http.MethodGet, "/users/7?limit=500", nil)
+	req.Header.Set("X-Token", "secret")
+	code, resp = bindRequest(t, req, bind)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, resp, `"field":"page.limit"`)
+}
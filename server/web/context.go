@@ -1,14 +1,18 @@
 package web
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"io"
+	"io/fs"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"sync"
 	"time"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
 	"github.com/hyper-micro/hyper/internal/json"
 	"github.com/spf13/cast"
@@ -18,8 +22,24 @@ type Ctx interface {
 	context.Context
 
 	Request() *http.Request
+	Writer() http.ResponseWriter
+	// SetRequest replaces the request, such as with one carrying a derived
+	// context, for the middleware and handler that follow.
+	SetRequest(r *http.Request)
+	// SetWriter replaces the response writer, such as with one that
+	// compresses or records the response.
+	SetWriter(w http.ResponseWriter)
 	Abort()
 	IsAbort() bool
+	// Locale is the bundled locale of validation messages, chosen from the
+	// Accept-Language header, then Option.Locale, then DefaultLocale.
+	Locale() string
+	// Error renders err with the server's ErrorHandler and aborts the
+	// request. A nil err is ignored.
+	Error(err error)
+	// Written reports whether the response status has been sent, through
+	// the Ctx or its Writer.
+	Written() bool
 
 	Set(key string, value any)
 	Get(key string) (value any, exists bool)
@@ -53,17 +73,64 @@ type Ctx interface {
 	FormFileHeader(name string) (*multipart.FileHeader, error)
 
 	JsonBinding(d any) error
+	// ShouldBind decodes the body into d by its Content-Type, or binds the
+	// query of GET and HEAD requests, then validates d. Errors are a
+	// *BindingError, or an HTTPError for unsupported content types.
+	ShouldBind(d any) error
+	// ShouldBindQuery, ShouldBindUri and ShouldBindHeader bind the fields
+	// of d tagged query, uri or header, such as `query:"page,default=1"`,
+	// then validate d.
+	ShouldBindQuery(d any) error
+	ShouldBindUri(d any) error
+	ShouldBindHeader(d any) error
+	// Bind, BindQuery, BindUri and BindHeader are like their ShouldBind
+	// counterparts but also pass errors to Error.
+	Bind(d any) error
+	BindQuery(d any) error
+	BindUri(d any) error
+	BindHeader(d any) error
 
 	GetHeader(key string) string
 	Header(key, value string)
 	Cookie(name string) (string, error)
 	SetCookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool, sameSite http.SameSite)
+	// Session loads the client's session from Option.Session on first use.
+	Session() *Session
 
 	Status(code int)
 	ResponseWithStatus(code int, data []byte) error
 	Response(data []byte) error
 	Json(data any) error
 	String(data string) error
+	XML(data any) error
+	Msgpack(data any) error
+	// HTML renders the named template with Option.HTML. Nothing is written
+	// if rendering fails.
+	HTML(name string, data any) error
+	// JSONP wraps the JSON of data in a call of the function named by the
+	// callback query parameter, or is Json when there is none.
+	JSONP(data any) error
+	// Negotiate renders data as JSON, XML or msgpack, as the Accept header
+	// prefers, or returns a 406 HTTPError if it accepts none of them.
+	// NegotiateFormat picks from other offered media types.
+	Negotiate(data any) error
+	NegotiateFormat(offered ...string) string
+	NoContent()
+	// Redirect replies with a 3xx code and location, which may be relative
+	// to the request path.
+	Redirect(code int, location string)
+	// File, FileFS and Attachment serve a file with support for
+	// conditional and Range requests. Attachment asks the client to save it
+	// as filename.
+	File(name string)
+	FileFS(fsys fs.FS, name string)
+	Attachment(name, filename string)
+	// Stream calls step, flushing after each call, until it returns false
+	// or the client goes away, in which case Stream returns true.
+	Stream(step func(w io.Writer) bool) bool
+	// SSE starts a stream of server-sent events, which must be closed
+	// before the handler returns.
+	SSE(opt ...SSEOption) (*EventStream, error)
 }
 
 type ctx struct {
@@ -77,12 +144,12 @@ type ctx struct {
 	queryCache url.Values
 	formCache  url.Values
 	status     bool
+	locale     string
+	session    *Session
 }
 
 const requestCtxKey = "_hyper/contextKey"
 
-var validate = validator.New(validator.WithRequiredStructEnabled())
-
 func makeContext(srv *Server, w http.ResponseWriter, r *http.Request) *ctx {
 	c := r.Context()
 	cCtx, ok := c.Value(requestCtxKey).(*ctx)
@@ -94,20 +161,74 @@ func makeContext(srv *Server, w http.ResponseWriter, r *http.Request) *ctx {
 }
 
 func newContext(c context.Context, srv *Server, w http.ResponseWriter, r *http.Request) *ctx {
-	return &ctx{
+	cCtx := &ctx{
 		ctx: c,
 		srv: srv,
 		mu:  new(sync.RWMutex),
-		w:   w,
 		r:   r,
 		kv:  make(map[string]any),
 	}
+	cCtx.w = &ctxWriter{ResponseWriter: w, c: cCtx}
+	return cCtx
+}
+
+// ctxWriter marks the response as written when a handler writes through
+// Writer directly, such as with http.ServeContent, so Written stays true
+// to what the client received.
+type ctxWriter struct {
+	http.ResponseWriter
+	c *ctx
+}
+
+func (w *ctxWriter) WriteHeader(code int) {
+	// Informational responses are followed by the final one.
+	if code >= http.StatusOK || code == http.StatusSwitchingProtocols {
+		w.c.status = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *ctxWriter) Write(b []byte) (int, error) {
+	w.c.status = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *ctxWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *ctxWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("web: %T does not support hijacking", w.ResponseWriter)
+	}
+	w.c.status = true
+	return h.Hijack()
+}
+
+func (w *ctxWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
 
 func (c *ctx) Request() *http.Request {
 	return c.r
 }
 
+func (c *ctx) Writer() http.ResponseWriter {
+	return c.w
+}
+
+func (c *ctx) SetRequest(r *http.Request) {
+	c.r = r
+	c.ctx = r.Context()
+}
+
+func (c *ctx) SetWriter(w http.ResponseWriter) {
+	c.w = w
+}
+
 func (c *ctx) Abort() {
 	c.abort = true
 }
@@ -116,6 +237,31 @@ func (c *ctx) IsAbort() bool {
 	return c.abort
 }
 
+func (c *ctx) Locale() string {
+	if c.locale == "" {
+		if locale, ok := matchLocale(c.r.Header.Get("Accept-Language")); ok {
+			c.locale = locale
+		} else if locale, ok := matchLocale(c.srv.Locale); ok {
+			c.locale = locale
+		} else {
+			c.locale = DefaultLocale
+		}
+	}
+	return c.locale
+}
+
+func (c *ctx) Error(err error) {
+	if err == nil {
+		return
+	}
+	c.srv.handleError(c, err)
+	c.Abort()
+}
+
+func (c *ctx) Written() bool {
+	return c.status
+}
+
 /// kv
 
 func (c *ctx) Set(key string, value any) {
@@ -318,11 +464,7 @@ func (c *ctx) Header(key, value string) {
 /// Binding struct
 
 func (c *ctx) JsonBinding(d any) error {
-	dc := json.NewDecoder(c.r.Body)
-	if err := dc.Decode(d); err != nil {
-		return err
-	}
-	return validate.Struct(d)
+	return decodeBody(d, decodeJSON, c.r.Body, c.Locale())
 }
 
 /// Cookie
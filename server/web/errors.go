@@ -0,0 +1,140 @@
+package web
+
+import (
+	stdErrors "errors"
+	"net/http"
+
+	"github.com/hyper-micro/hyper/errors"
+	"github.com/hyper-micro/hyper/internal/json"
+)
+
+// ErrorHandler renders an error returned by a handler or passed to
+// Ctx.Error.
+type ErrorHandler func(ctx Ctx, err error)
+
+// HandlerE is a handler that returns its error for the server's
+// ErrorHandler to render.
+type HandlerE func(ctx Ctx) error
+
+// WithError adapts f to a Handler, passing a non-nil error to Ctx.Error.
+func WithError(f HandlerE) Handler {
+	return func(ctx Ctx) {
+		if err := f(ctx); err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// HTTPError is an error answered with its HTTP status code.
+type HTTPError struct {
+	Code    int
+	Message string
+}
+
+// NewHTTPError returns an HTTPError with the given status code. The
+// message defaults to the status text.
+func NewHTTPError(code int, message ...string) *HTTPError {
+	e := &HTTPError{Code: code, Message: http.StatusText(code)}
+	if len(message) > 0 {
+		e.Message = message[0]
+	}
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+type errorBody struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// DefaultErrorHandler answers errors as JSON of the form
+// {"code": ..., "message": ...}.
+//
+// An HTTPError is answered with its status code, a BindingError 400 with
+// its field errors and an http.MaxBytesError 413. An errors.Errors is
+// answered with its code in the body, and with it as the status too when
+// it is a 4xx or 5xx status, or 400 otherwise. Any other error is answered
+// 500 without exposing its message. Nothing is written once the response
+// has started.
+func DefaultErrorHandler(ctx Ctx, err error) {
+	if ctx.Written() {
+		return
+	}
+	status, body := errorResponse(err)
+	b, err := json.Marshal(body)
+	if err != nil {
+		return
+	}
+	ctx.Header("Content-Type", "application/json; charset=utf-8")
+	_ = ctx.ResponseWithStatus(status, b)
+}
+
+func errorResponse(err error) (int, errorBody) {
+	var (
+		httpErr *HTTPError
+		codeErr *errors.Errors
+		bindErr *BindingError
+		sizeErr *http.MaxBytesError
+	)
+	switch {
+	case stdErrors.As(err, &sizeErr):
+		return http.StatusRequestEntityTooLarge, errorBody{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: http.StatusText(http.StatusRequestEntityTooLarge),
+		}
+	case stdErrors.As(err, &httpErr):
+		return httpErr.Code, errorBody{Code: httpErr.Code, Message: httpErr.Message}
+	case stdErrors.As(err, &bindErr):
+		return http.StatusBadRequest, errorBody{Code: http.StatusBadRequest, Message: bindErr.Error(), Fields: bindErr.Fields}
+	case stdErrors.As(err, &codeErr):
+		status := http.StatusBadRequest
+		if codeErr.Code() >= 400 && codeErr.Code() < 600 {
+			status = codeErr.Code()
+		}
+		return status, errorBody{Code: codeErr.Code(), Message: codeErr.Message()}
+	}
+	return http.StatusInternalServerError, errorBody{
+		Code:    http.StatusInternalServerError,
+		Message: http.StatusText(http.StatusInternalServerError),
+	}
+}
+
+// isExpected reports whether err was returned on purpose to answer the
+// client, rather than being a failure worth logging.
+func isExpected(err error) bool {
+	var (
+		httpErr *HTTPError
+		codeErr *errors.Errors
+		bindErr *BindingError
+		sizeErr *http.MaxBytesError
+	)
+	return stdErrors.As(err, &httpErr) || stdErrors.As(err, &codeErr) ||
+		stdErrors.As(err, &bindErr) || stdErrors.As(err, &sizeErr)
+}
+
+func (s *Server) handleError(c *ctx, err error) {
+	if !isExpected(err) {
+		s.logger().Errorf("web: %s %s: %v", c.r.Method, c.r.URL.Path, err)
+	}
+	s.renderError(c, err)
+}
+
+func (s *Server) renderError(c *ctx, err error) {
+	if s.ErrorHandler != nil {
+		s.ErrorHandler(c, err)
+		return
+	}
+	DefaultErrorHandler(c, err)
+}
+
+func notFound(ctx Ctx) {
+	ctx.Error(NewHTTPError(http.StatusNotFound))
+}
+
+func methodNotAllowed(ctx Ctx) {
+	ctx.Error(NewHTTPError(http.StatusMethodNotAllowed))
+}
@@ -0,0 +1,87 @@
+package web
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyper-micro/hyper/errors"
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ErrorHandler(t *testing.T) {
+	log := loggertest.New()
+	srv := New(Option{Logger: log})
+	var middleware int
+	srv.Use(func(ctx Ctx, next func()) {
+		middleware++
+		next()
+	})
+	srv.Get("/coded", WithError(func(ctx Ctx) error {
+		return errors.Wrap(errors.New(10001, "user not found"), stdErrors.New("lookup"))
+	}))
+	srv.Get("/status", WithError(func(ctx Ctx) error {
+		return errors.New(http.StatusForbidden, "forbidden")
+	}))
+	srv.Get("/http", WithError(func(ctx Ctx) error {
+		return NewHTTPError(http.StatusConflict)
+	}))
+	srv.Get("/internal", WithError(func(ctx Ctx) error {
+		return stdErrors.New("dial tcp: refused")
+	}))
+	srv.Group("/api").Get("/users", func(ctx Ctx) {})
+	srv.Get("/ok", WithError(func(ctx Ctx) error {
+		return ctx.String("ok")
+	}))
+
+	for path, want := range map[string]struct {
+		code int
+		body string
+	}{
+		"/coded":    {http.StatusBadRequest, `{"code":10001,"message":"user not found"}`},
+		"/status":   {http.StatusForbidden, `{"code":403,"message":"forbidden"}`},
+		"/http":     {http.StatusConflict, `{"code":409,"message":"Conflict"}`},
+		"/internal": {http.StatusInternalServerError, `{"code":500,"message":"Internal Server Error"}`},
+		"/ok":       {http.StatusOK, "ok"},
+		"/missing":  {http.StatusNotFound, `{"code":404,"message":"Not Found"}`},
+		"/api/none": {http.StatusNotFound, `{"code":404,"message":"Not Found"}`},
+	} {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want.code, rec.Code, path)
+		assert.Equal(t, want.body, rec.Body.String(), path)
+	}
+	assert.Equal(t, 7, middleware)
+	log.AssertLogged(t, logger.ErrorLevel, "web: GET /internal: dial tcp: refused")
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ok", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+}
+
+func TestServer_CustomErrorHandlers(t *testing.T) {
+	srv := New(Option{
+		ErrorHandler: func(ctx Ctx, err error) {
+			_ = ctx.ResponseWithStatus(http.StatusTeapot, []byte(err.Error()))
+		},
+		NotFound: func(ctx Ctx) {
+			_ = ctx.ResponseWithStatus(http.StatusNotFound, []byte("nothing here"))
+		},
+	})
+	srv.Get("/fail", WithError(func(ctx Ctx) error {
+		return stdErrors.New("fail")
+	}))
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "fail", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Equal(t, "nothing here", rec.Body.String())
+}
@@ -0,0 +1,220 @@
+package web
+
+import (
+	"encoding"
+	stdErrors "errors"
+	"fmt"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// valueSource looks up the values bound to a field name.
+type valueSource func(name string) ([]string, bool)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// bindValues sets the fields of the struct d points to from values, named
+// by the tag key of each field, such as `query:"page,default=1"`. Fields
+// without the tag are looked up by their Go name, except untagged structs
+// whose fields are bound as if declared inline; a tag of "-" skips the
+// field. Files are bound to *multipart.FileHeader and
+// []*multipart.FileHeader fields.
+func bindValues(d any, tag string, values valueSource, files map[string][]*multipart.FileHeader) error {
+	rv := reflect.ValueOf(d)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("web: cannot bind %s values into %T, want a pointer to a struct", tag, d)
+	}
+	b := &formBinder{tag: tag, values: values, files: files}
+	b.bindStruct(rv.Elem())
+	if len(b.fields) > 0 {
+		return &BindingError{Fields: b.fields}
+	}
+	return nil
+}
+
+type formBinder struct {
+	tag    string
+	values valueSource
+	files  map[string][]*multipart.FileHeader
+	fields []FieldError
+}
+
+func (b *formBinder) bindStruct(rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() && !sf.Anonymous {
+			continue
+		}
+		fv := rv.Field(i)
+		name, def, hasDef := parseBindingTag(sf.Tag.Get(b.tag))
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if sf.Type.Kind() == reflect.Struct && !isScalarType(sf.Type) {
+				b.bindStruct(fv)
+				continue
+			}
+			name = sf.Name
+		}
+		if !fv.CanSet() {
+			continue
+		}
+
+		switch sf.Type {
+		case fileHeaderType:
+			if fhs := b.files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs[0]))
+			}
+			continue
+		case reflect.SliceOf(fileHeaderType):
+			if fhs := b.files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs))
+			}
+			continue
+		}
+
+		vals, ok := b.values(name)
+		if !ok || len(vals) == 0 {
+			if !hasDef {
+				continue
+			}
+			vals = []string{def}
+		}
+		if err := setField(fv, vals); err != nil {
+			b.fields = append(b.fields, FieldError{
+				Field:   name,
+				Tag:     "type",
+				Param:   sf.Type.String(),
+				Message: fmt.Sprintf("%s must be a valid %s", name, sf.Type),
+			})
+		}
+	}
+}
+
+// parseBindingTag splits a tag such as "page,default=1".
+func parseBindingTag(tag string) (name, def string, hasDef bool) {
+	name, opts, _ := strings.Cut(tag, ",")
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if v, ok := strings.CutPrefix(opt, "default="); ok {
+			def, hasDef = v, true
+		}
+	}
+	return name, def, hasDef
+}
+
+// isScalarType reports whether t is set from a single value even though
+// it is a struct, such as time.Time.
+func isScalarType(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func setField(fv reflect.Value, vals []string) error {
+	switch fv.Kind() {
+	case reflect.Pointer:
+		if isScalarType(fv.Type().Elem()) || fv.Type().Elem().Kind() != reflect.Struct {
+			nv := reflect.New(fv.Type().Elem())
+			if err := setField(nv.Elem(), vals); err != nil {
+				return err
+			}
+			fv.Set(nv)
+			return nil
+		}
+	case reflect.Slice:
+		if !isScalarType(fv.Type()) {
+			sv := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+			for i, v := range vals {
+				if err := setValue(sv.Index(i), v); err != nil {
+					return err
+				}
+			}
+			fv.Set(sv)
+			return nil
+		}
+	case reflect.Array:
+		if len(vals) > fv.Len() {
+			return stdErrors.New("too many values")
+		}
+		for i, v := range vals {
+			if err := setValue(fv.Index(i), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return setValue(fv, vals[0])
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if s == "" {
+				v.SetZero()
+				return nil
+			}
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+	if v.Kind() == reflect.Pointer {
+		nv := reflect.New(v.Type().Elem())
+		if err := setValue(nv.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(nv)
+		return nil
+	}
+	if v.Kind() != reflect.String && s == "" {
+		v.SetZero()
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyper-micro/hyper/tracing"
+)
+
+// unmatchedRoute labels requests that match no route, keeping the raw
+// path out of metric labels and span names.
+const unmatchedRoute = "unmatched"
+
+// otherMethod labels requests with a method outside the standard set, as
+// the server accepts any method token.
+const otherMethod = "other"
+
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return otherMethod
+}
+
+type routeKey struct{}
+
+// instrument records metrics and a server span for every request, named
+// after the matched route template.
+func (s *Server) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		method := methodLabel(req.Method)
+		route := unmatchedRoute
+		ctx := context.WithValue(req.Context(), routeKey{}, &route)
+
+		var span *tracing.Span
+		if s.Tracer != nil {
+			ctx = tracing.Extract(ctx, tracing.HeaderCarrier(req.Header))
+			ctx, span = s.Tracer.Start(ctx, method, tracing.WithKind(tracing.SpanKindServer))
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, req.WithContext(ctx))
+
+		code := sw.status
+		if code == 0 {
+			code = http.StatusOK
+		}
+		if s.metrics != nil {
+			s.metrics.observe(method, route, code, time.Since(start))
+		}
+		if span != nil {
+			span.SetName(method + " " + route)
+			span.SetAttribute("http.request.method", method)
+			span.SetAttribute("http.route", route)
+			span.SetAttribute("url.path", req.URL.Path)
+			span.SetAttribute("http.response.status_code", code)
+			if code >= http.StatusInternalServerError {
+				span.SetError(http.StatusText(code))
+			}
+			span.End()
+		}
+	})
+}
+
+// recordRoute reports the matched route template to instrument.
+func recordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if route, ok := req.Context().Value(routeKey{}).(*string); ok {
+			if tpl, err := mux.CurrentRoute(req).GetPathTemplate(); err == nil {
+				*route = tpl
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// statusWriter records the response status while keeping the optional
+// interfaces of the underlying writer reachable.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("web: %T does not support hijacking", w.ResponseWriter)
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,64 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyper-micro/hyper/internal/json"
+	"github.com/hyper-micro/hyper/metrics"
+	"github.com/hyper-micro/hyper/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	srv := New(Option{Metrics: reg})
+	srv.PathPrefix("/api").Get("/users/{id}", func(ctx Ctx) {
+		_ = ctx.ResponseWithStatus(http.StatusCreated, nil)
+	})
+
+	for _, path := range []string{"/api/users/1", "/api/users/2", "/missing"} {
+		srv.srv.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	// Unknown methods share one label rather than adding series.
+	for _, method := range []string{"FOO", "BAR"} {
+		srv.srv.Handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/missing", nil))
+	}
+
+	var b strings.Builder
+	require.NoError(t, reg.WriteText(&b))
+	assert.Contains(t, b.String(), `http_requests_total{method="GET",route="/api/users/{id}",code="201"} 2`)
+	assert.Contains(t, b.String(), `http_requests_total{method="GET",route="unmatched",code="404"} 1`)
+	assert.Contains(t, b.String(), `http_requests_total{method="other",route="unmatched",code="404"} 2`)
+	assert.NotContains(t, b.String(), `FOO`)
+	assert.Contains(t, b.String(), `http_request_duration_seconds_count{method="GET",route="/api/users/{id}"} 2`)
+}
+
+func TestServer_Tracing(t *testing.T) {
+	var out bytes.Buffer
+	tracer := tracing.New(tracing.Option{Exporter: tracing.NewWriterExporter(&out)})
+	srv := New(Option{Tracer: tracer})
+	srv.Get("/orders/{id}", func(ctx Ctx) {
+		span := tracing.SpanFromContext(ctx)
+		span.SetAttribute("order.id", ctx.Param("id"))
+		_ = ctx.ResponseWithStatus(http.StatusInternalServerError, nil)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	srv.srv.Handler.ServeHTTP(httptest.NewRecorder(), req)
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	var span tracing.SpanData
+	require.NoError(t, json.Unmarshal(out.Bytes(), &span))
+	assert.Equal(t, "GET /orders/{id}", span.Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentID)
+	assert.Equal(t, "7", span.Attributes["order.id"])
+	assert.Equal(t, "Internal Server Error", span.Error)
+}
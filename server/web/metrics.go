@@ -0,0 +1,25 @@
+package web
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hyper-micro/hyper/metrics"
+)
+
+type serverMetrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+}
+
+func newServerMetrics(r *metrics.Registry) *serverMetrics {
+	return &serverMetrics{
+		requests: r.NewCounter("http_requests_total", "HTTP requests handled, by route template.", "method", "route", "code"),
+		duration: r.NewHistogram("http_request_duration_seconds", "HTTP request latency, by route template.", metrics.DefBuckets, "method", "route"),
+	}
+}
+
+func (m *serverMetrics) observe(method, route string, code int, d time.Duration) {
+	m.requests.Inc(method, route, strconv.Itoa(code))
+	m.duration.Observe(d.Seconds(), method, route)
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+type AccessLogOption struct {
+	// Logger defaults to logger.Default().
+	Logger logger.Logger
+	// Skip excludes requests from the log, such as health checks.
+	Skip func(ctx web.Ctx) bool
+}
+
+// AccessLog logs every request once it is served, at error level for 5xx
+// responses, warn level for 4xx and info level otherwise.
+func AccessLog(opt AccessLogOption) web.MiddlewareHandler {
+	return func(ctx web.Ctx, next func()) {
+		if opt.Skip != nil && opt.Skip(ctx) {
+			next()
+			return
+		}
+
+		start := time.Now()
+		rw := &recordWriter{ResponseWriter: ctx.Writer()}
+		ctx.SetWriter(rw)
+		defer ctx.SetWriter(rw.ResponseWriter)
+		next()
+
+		log := opt.Logger
+		if log == nil {
+			log = logger.Default()
+		}
+		req := ctx.Request()
+		log = log.With(
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", rw.Status(),
+			"size", rw.size,
+			"duration", time.Since(start),
+			"ip", ClientIP(ctx),
+			"userAgent", req.UserAgent(),
+		)
+		if id := GetRequestID(ctx); id != "" {
+			log = log.With("requestId", id)
+		}
+
+		const msg = "http request"
+		switch status := rw.Status(); {
+		case status >= 500:
+			log.Error(msg)
+		case status >= 400:
+			log.Warn(msg)
+		default:
+			log.Info(msg)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hyper-micro/hyper/auth"
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+const principalKey = "_hyper/principal"
+
+type AuthOption struct {
+	Authenticator auth.Authenticator
+	// Optional lets requests without credentials through unauthenticated,
+	// leaving guards to reject them. Invalid credentials are still
+	// answered 401.
+	Optional bool
+	// Challenge is sent in WWW-Authenticate with 401 responses, such as
+	// `Bearer realm="api"`.
+	Challenge string
+}
+
+// Auth authenticates requests, answering 401 to those without valid
+// credentials. The principal is stored for GetPrincipal and in the
+// request context for auth.PrincipalFromContext.
+func Auth(opt AuthOption) web.MiddlewareHandler {
+	return func(ctx web.Ctx, next func()) {
+		p, err := opt.Authenticator.Authenticate(ctx, ctx.Request().Header)
+		switch {
+		case err == nil:
+			ctx.Set(principalKey, p)
+			ctx.SetRequest(ctx.Request().WithContext(auth.ContextWithPrincipal(ctx.Request().Context(), p)))
+		case errors.Is(err, auth.ErrNoCredentials) && opt.Optional:
+		case errors.Is(err, auth.ErrNoCredentials), errors.Is(err, auth.ErrInvalidCredentials):
+			unauthorized(ctx, opt.Challenge)
+			return
+		default:
+			ctx.Error(err)
+			return
+		}
+		next()
+	}
+}
+
+// GetPrincipal returns the principal authenticated by Auth, or nil.
+func GetPrincipal(ctx web.Ctx) *auth.Principal {
+	p, _ := ctx.Value(principalKey).(*auth.Principal)
+	return p
+}
+
+func unauthorized(ctx web.Ctx, challenge string) {
+	if challenge != "" {
+		ctx.Header("WWW-Authenticate", challenge)
+	}
+	ctx.Error(web.NewHTTPError(http.StatusUnauthorized))
+}
+
+// RequireRoles answers 403 unless the principal has at least one of
+// roles, or 401 if the request is not authenticated.
+func RequireRoles(roles ...string) web.MiddlewareHandler {
+	return guard(func(p *auth.Principal) bool {
+		return p.HasAnyRole(roles...)
+	})
+}
+
+// RequireScopes answers 403 unless the principal has every one of scopes,
+// or 401 if the request is not authenticated.
+func RequireScopes(scopes ...string) web.MiddlewareHandler {
+	return guard(func(p *auth.Principal) bool {
+		return p.HasScopes(scopes...)
+	})
+}
+
+func guard(allow func(p *auth.Principal) bool) web.MiddlewareHandler {
+	return func(ctx web.Ctx, next func()) {
+		p := GetPrincipal(ctx)
+		if p == nil {
+			unauthorized(ctx, "")
+			return
+		}
+		if !allow(p) {
+			ctx.Error(web.NewHTTPError(http.StatusForbidden))
+			return
+		}
+		next()
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+// BodyLimit answers 413 to requests declaring a body longer than limit
+// bytes and stops reading bodies at limit, making reads fail with an
+// *http.MaxBytesError.
+func BodyLimit(limit int64) web.MiddlewareHandler {
+	return func(ctx web.Ctx, next func()) {
+		req := ctx.Request()
+		if req.ContentLength > limit {
+			ctx.Error(web.NewHTTPError(http.StatusRequestEntityTooLarge))
+			return
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			req.Body = http.MaxBytesReader(ctx.Writer(), req.Body, limit)
+		}
+		next()
+	}
+}
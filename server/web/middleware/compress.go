@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+const (
+	encodingGzip   = "gzip"
+	encodingBrotli = "br"
+)
+
+type CompressOption struct {
+	// Level applies to both encodings and defaults to a speed-oriented
+	// level of each.
+	Level int
+	// MinLength is the size below which responses are sent uncompressed,
+	// defaulting to 1024 bytes.
+	MinLength int
+	// ContentTypes lists compressible media types, defaulting to text,
+	// JSON, XML and JavaScript. A trailing "*" matches any subtype.
+	ContentTypes []string
+}
+
+var defaultCompressTypes = []string{
+	"text/*",
+	"application/json",
+	"application/xml",
+	"application/javascript",
+	"application/x-javascript",
+	"image/svg+xml",
+}
+
+// Compress encodes responses with brotli or gzip, as the client prefers,
+// when their content type is compressible and they reach MinLength.
+func Compress(opt CompressOption) web.MiddlewareHandler {
+	if opt.MinLength <= 0 {
+		opt.MinLength = 1024
+	}
+	if len(opt.ContentTypes) == 0 {
+		opt.ContentTypes = defaultCompressTypes
+	}
+	return func(ctx web.Ctx, next func()) {
+		ctx.Writer().Header().Add("Vary", "Accept-Encoding")
+		req := ctx.Request()
+		encoding := negotiateEncoding(req.Header.Get("Accept-Encoding"))
+		if encoding == "" || req.Method == http.MethodHead || req.Header.Get("Upgrade") != "" {
+			next()
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: ctx.Writer(), opt: &opt, encoding: encoding}
+		ctx.SetWriter(cw)
+		defer func() {
+			ctx.SetWriter(cw.ResponseWriter)
+			_ = cw.Close()
+		}()
+		next()
+	}
+}
+
+// negotiateEncoding picks br or gzip from an Accept-Encoding header by
+// quality, preferring br on a tie.
+func negotiateEncoding(accept string) string {
+	var best string
+	bestQ := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != encodingGzip && coding != encodingBrotli {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > bestQ || (q == bestQ && q > 0 && coding == encodingBrotli) {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response until it can tell
+// whether to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	opt      *CompressOption
+	encoding string
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if code < http.StatusOK {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.status = code
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		_ = w.start(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.opt.MinLength {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// start decides on compression and sends the header and buffered body.
+func (w *compressWriter) start(compress bool) error {
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	// Ranges address the identity body, so partial responses stay as they
+	// are.
+	compress = compress && h.Get("Content-Encoding") == "" && w.compressible(h.Get("Content-Type")) &&
+		w.status != http.StatusPartialContent && h.Get("Content-Range") == ""
+	if compress {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		// The encoded body is a different representation, so a strong ETag
+		// of the handler must not validate it, such as in If-Range.
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		if w.encoding == encodingBrotli {
+			level := w.opt.Level
+			if level == 0 {
+				level = 4
+			}
+			w.enc = brotli.NewWriterLevel(w.ResponseWriter, level)
+		} else {
+			level := w.opt.Level
+			if level == 0 {
+				level = gzip.BestSpeed
+			}
+			gw, err := gzip.NewWriterLevel(w.ResponseWriter, level)
+			if err != nil {
+				gw = gzip.NewWriter(w.ResponseWriter)
+			}
+			w.enc = gw
+		}
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	buf := w.buf
+	w.buf = nil
+	if w.enc != nil {
+		_, err := w.enc.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+func (w *compressWriter) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range w.opt.ContentTypes {
+		if prefix, ok := strings.CutSuffix(t, "*"); ok {
+			if strings.HasPrefix(mediaType, prefix) {
+				return true
+			}
+		} else if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Close sends what is still buffered, uncompressed if it is shorter than
+// MinLength, and ends the encoded stream.
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if err := w.start(false); err != nil {
+			return err
+		}
+	}
+	if w.enc != nil {
+		return w.enc.Close()
+	}
+	return nil
+}
+
+// Flush compresses whatever has been written, as streaming responses must
+// not wait for MinLength.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		_ = w.start(true)
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("middleware: %T does not support hijacking", w.ResponseWriter)
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+type CORSOption struct {
+	// AllowOrigins lists allowed origins, such as "https://example.com",
+	// "https://*.example.com" or "*" for any origin. "*" cannot be combined
+	// with AllowCredentials.
+	AllowOrigins []string
+	// AllowMethods defaults to GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowMethods []string
+	// AllowHeaders defaults to echoing the preflight's requested headers.
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS answers preflight requests with 204 and adds CORS headers to the
+// responses of allowed origins. Requests from other origins are served
+// without CORS headers, leaving the browser to block them. It panics if
+// AllowOrigins contains "*" and AllowCredentials is set, which would let any
+// site make credentialed requests.
+func CORS(opt CORSOption) web.MiddlewareHandler {
+	if len(opt.AllowMethods) == 0 {
+		opt.AllowMethods = []string{
+			http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPut, http.MethodPatch, http.MethodDelete,
+		}
+	}
+	allowMethods := strings.Join(opt.AllowMethods, ", ")
+	allowHeaders := strings.Join(opt.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(opt.ExposeHeaders, ", ")
+	anyOrigin := false
+	for _, o := range opt.AllowOrigins {
+		anyOrigin = anyOrigin || o == "*"
+	}
+	if anyOrigin && opt.AllowCredentials {
+		panic(`middleware: CORS cannot allow credentials for origin "*"`)
+	}
+
+	return func(ctx web.Ctx, next func()) {
+		origin := ctx.GetHeader("Origin")
+		h := ctx.Writer().Header()
+		h.Add("Vary", "Origin")
+		if origin == "" || !(anyOrigin || matchOrigin(opt.AllowOrigins, origin)) {
+			next()
+			return
+		}
+
+		if anyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if opt.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		req := ctx.Request()
+		if req.Method != http.MethodOptions || req.Header.Get("Access-Control-Request-Method") == "" {
+			if exposeHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			next()
+			return
+		}
+
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			h.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if requested := req.Header.Get("Access-Control-Request-Headers"); requested != "" {
+			h.Set("Access-Control-Allow-Headers", requested)
+		}
+		if opt.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(opt.MaxAge.Seconds())))
+		}
+		ctx.Status(http.StatusNoContent)
+		ctx.Abort()
+	}
+}
+
+func matchOrigin(allowed []string, origin string) bool {
+	for _, a := range allowed {
+		if prefix, suffix, ok := strings.Cut(a, "*"); ok {
+			if len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+const (
+	csrfTokenKey  = "_hyper/csrfToken"
+	csrfTokenSize = 32
+)
+
+type CSRFOption struct {
+	// CookieName holds the secret token and defaults to "_csrf".
+	CookieName string
+	// Header and FormField carry the token of unsafe requests, defaulting
+	// to X-CSRF-Token and "_csrf".
+	Header    string
+	FormField string
+	// MaxAge of the cookie defaults to 12 hours.
+	MaxAge time.Duration
+	Path   string
+	Domain string
+	Secure bool
+	// SameSite defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// TrustedOrigins may send unsafe requests besides the request's own
+	// host, such as "app.example.com".
+	TrustedOrigins []string
+	// Skip exempts requests, such as webhooks authenticated otherwise.
+	Skip func(ctx web.Ctx) bool
+}
+
+// CSRF rejects POST, PUT, PATCH and DELETE requests with 403 unless they
+// send the token of CSRFToken in the header or form field, and come from
+// the same or a trusted origin when they carry an Origin header.
+func CSRF(opt CSRFOption) web.MiddlewareHandler {
+	if opt.CookieName == "" {
+		opt.CookieName = "_csrf"
+	}
+	if opt.Header == "" {
+		opt.Header = "X-CSRF-Token"
+	}
+	if opt.FormField == "" {
+		opt.FormField = "_csrf"
+	}
+	if opt.MaxAge <= 0 {
+		opt.MaxAge = 12 * time.Hour
+	}
+	if opt.SameSite == 0 {
+		opt.SameSite = http.SameSiteLaxMode
+	}
+	return func(ctx web.Ctx, next func()) {
+		if opt.Skip != nil && opt.Skip(ctx) {
+			next()
+			return
+		}
+
+		cookie, _ := ctx.Cookie(opt.CookieName)
+		secret := decodeToken(cookie, csrfTokenSize)
+		if secret == nil {
+			secret = make([]byte, csrfTokenSize)
+			_, _ = rand.Read(secret)
+			ctx.SetCookie(opt.CookieName, base64.RawURLEncoding.EncodeToString(secret),
+				int(opt.MaxAge.Seconds()), opt.Path, opt.Domain, opt.Secure, true, opt.SameSite)
+		}
+		ctx.Set(csrfTokenKey, maskToken(secret))
+		ctx.Writer().Header().Add("Vary", "Cookie")
+
+		switch ctx.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			next()
+			return
+		}
+		if !sameOrigin(ctx.Request(), opt.TrustedOrigins) {
+			ctx.Error(web.NewHTTPError(http.StatusForbidden, "origin not allowed"))
+			return
+		}
+		sent := ctx.GetHeader(opt.Header)
+		if sent == "" {
+			sent = ctx.PostFormString(opt.FormField)
+		}
+		if !validToken(sent, secret) {
+			ctx.Error(web.NewHTTPError(http.StatusForbidden, "invalid CSRF token"))
+			return
+		}
+		next()
+	}
+}
+
+// CSRFToken returns the token to send with unsafe requests, such as in a
+// hidden form field. It changes on every request, but stays valid as long
+// as the CSRF cookie does.
+func CSRFToken(ctx web.Ctx) string {
+	return ctx.GetString(csrfTokenKey)
+}
+
+func decodeToken(s string, size int) []byte {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != size {
+		return nil
+	}
+	return b
+}
+
+// maskToken XORs secret with a random pad sent along with it, so responses
+// do not repeat the secret, which would let compression leak it.
+func maskToken(secret []byte) string {
+	b := make([]byte, 2*len(secret))
+	pad := b[:len(secret)]
+	_, _ = rand.Read(pad)
+	for i := range secret {
+		b[len(secret)+i] = pad[i] ^ secret[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func validToken(token string, secret []byte) bool {
+	b := decodeToken(token, 2*len(secret))
+	if b == nil {
+		return false
+	}
+	pad, masked := b[:len(secret)], b[len(secret):]
+	for i := range masked {
+		masked[i] ^= pad[i]
+	}
+	return subtle.ConstantTimeCompare(masked, secret) == 1
+}
+
+func sameOrigin(r *http.Request, trusted []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if u.Host == r.Host {
+		return true
+	}
+	for _, host := range trusted {
+		if u.Host == host {
+			return true
+		}
+	}
+	return false
+}
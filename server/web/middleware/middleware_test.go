@@ -0,0 +1,377 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/hyper-micro/hyper/auth"
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/logger/loggertest"
+	"github.com/hyper-micro/hyper/ratelimit"
+	"github.com/hyper-micro/hyper/server/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(srv *web.Server, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	log := loggertest.New()
+	srv := web.New(web.Option{})
+	srv.Use(
+		RealIP(RealIPOption{TrustedProxies: []string{"10.0.0.0/8"}}),
+		RequestID(RequestIDOption{}),
+		AccessLog(AccessLogOption{Logger: log}),
+	)
+	var gotID, gotIP string
+	srv.Get("/", func(ctx web.Ctx) {
+		gotID, gotIP = GetRequestID(ctx), ClientIP(ctx)
+		_ = ctx.String("hello")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.1.2.3:4567"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 198.51.100.1, 10.0.0.2")
+	rec := serve(srv, req)
+	assert.Len(t, gotID, 32)
+	assert.Equal(t, gotID, rec.Header().Get(DefaultRequestIDHeader))
+	assert.Equal(t, "198.51.100.1", gotIP)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	req.Header.Set(DefaultRequestIDHeader, "abc-123")
+	serve(srv, req)
+	assert.Equal(t, "abc-123", gotID)
+	assert.Equal(t, "192.0.2.1", gotIP)
+
+	serve(srv, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	entries := log.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, logger.InfoLevel, entries[0].Level)
+	assert.Equal(t, 200, entries[0].Fields["status"])
+	assert.Equal(t, int64(5), entries[0].Fields["size"])
+	assert.Equal(t, "198.51.100.1", entries[0].Fields["ip"])
+	assert.Equal(t, "abc-123", entries[1].Fields["requestId"])
+	assert.Equal(t, logger.WarnLevel, entries[2].Level)
+	assert.Equal(t, 404, entries[2].Fields["status"])
+}
+
+func TestCORS(t *testing.T) {
+	srv := web.New(web.Option{})
+	srv.Use(CORS(CORSOption{
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowCredentials: true,
+		ExposeHeaders:    []string{"X-Total"},
+		MaxAge:           time.Hour,
+	}))
+	srv.Get("/items", func(ctx web.Ctx) {
+		_ = ctx.String("ok")
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	req.Header.Set("Access-Control-Request-Headers", "Authorization")
+	rec := serve(srv, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Authorization", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "3600", rec.Header().Get("Access-Control-Max-Age"))
+
+	req = httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = serve(srv, req)
+	assert.Equal(t, "ok", rec.Body.String())
+	assert.Equal(t, "X-Total", rec.Header().Get("Access-Control-Expose-Headers"))
+
+	req = httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	rec = serve(srv, req)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	assert.Panics(t, func() {
+		CORS(CORSOption{AllowOrigins: []string{"*"}, AllowCredentials: true})
+	})
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("hyper ", 500)
+	srv := web.New(web.Option{})
+	srv.Use(Compress(CompressOption{}))
+	srv.Get("/large", func(ctx web.Ctx) {
+		_ = ctx.String(large)
+	})
+	srv.Get("/small", func(ctx web.Ctx) {
+		_ = ctx.Json(map[string]string{"a": "b"})
+	})
+	srv.Get("/png", func(ctx web.Ctx) {
+		ctx.Header("Content-Type", "image/png")
+		_ = ctx.Response([]byte(large))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/large", nil)
+	req.Header.Set("Accept-Encoding", "gzip, br;q=0.5")
+	rec := serve(srv, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	zr, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	b, _ := io.ReadAll(zr)
+	assert.Equal(t, large, string(b))
+
+	req.Header.Set("Accept-Encoding", "gzip, br")
+	rec = serve(srv, req)
+	assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	b, _ = io.ReadAll(brotli.NewReader(rec.Body))
+	assert.Equal(t, large, string(b))
+
+	for _, path := range []string{"/small", "/png"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec = serve(srv, req)
+		assert.Empty(t, rec.Header().Get("Content-Encoding"), path)
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"), path)
+	}
+
+	srv.StaticFS("/static/", fstest.MapFS{"a.txt": {Data: []byte(large)}})
+	req = httptest.NewRequest(http.MethodGet, "/static/a.txt", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = serve(srv, req)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	etag := rec.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(etag, `W/"`), etag)
+
+	req.Header.Set("Range", "bytes=0-4")
+	rec = serve(srv, req)
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "hyper", rec.Body.String())
+
+	// A weakened ETag does not validate a range of the identity body.
+	req.Header.Set("If-Range", etag)
+	rec = serve(srv, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// slowFS takes d to open each file.
+type slowFS struct {
+	fs.FS
+	d time.Duration
+}
+
+func (f slowFS) Open(name string) (fs.File, error) {
+	time.Sleep(f.d)
+	return f.FS.Open(name)
+}
+
+func TestTimeout_Static(t *testing.T) {
+	srv := web.New(web.Option{})
+	srv.Use(Timeout(10 * time.Millisecond))
+	srv.StaticFS("/assets/", slowFS{FS: fstest.MapFS{"app.js": {Data: []byte("console.log(1)")}}, d: 20 * time.Millisecond})
+
+	rec := serve(srv, httptest.NewRequest(http.MethodGet, "/assets/app.js", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "console.log(1)", rec.Body.String())
+}
+
+func TestBodyLimitAndTimeout(t *testing.T) {
+	srv := web.New(web.Option{})
+	srv.Use(BodyLimit(8), Timeout(20*time.Millisecond))
+	srv.Post("/echo", func(ctx web.Ctx) {
+		b, err := io.ReadAll(ctx.Request().Body)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		_ = ctx.Response(b)
+	})
+	srv.Get("/slow", func(ctx web.Ctx) {
+		<-ctx.Request().Context().Done()
+	})
+
+	rec := serve(srv, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("tiny")))
+	assert.Equal(t, "tiny", rec.Body.String())
+
+	rec = serve(srv, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("far too long")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", io.MultiReader(strings.NewReader("far too long")))
+	req.ContentLength = -1
+	rec = serve(srv, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = serve(srv, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = serve(srv, httptest.NewRequest(http.MethodGet, "/slow", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestSecureHeaders(t *testing.T) {
+	opt := DefaultSecureHeadersOption
+	opt.HSTSMaxAge = 24 * time.Hour
+	srv := web.New(web.Option{})
+	srv.Use(SecureHeaders(opt))
+	srv.Get("/", func(ctx web.Ctx) {})
+
+	rec := serve(srv, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "SAMEORIGIN", rec.Header().Get("X-Frame-Options"))
+	assert.Empty(t, rec.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	rec = serve(srv, req)
+	assert.Equal(t, "max-age=86400", rec.Header().Get("Strict-Transport-Security"))
+}
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Option{Rate: ratelimit.PerMinute(1)})
+	srv := web.New(web.Option{})
+	srv.Get("/a", func(ctx web.Ctx) {}, RateLimit(RateLimitOption{Limiter: limiter, KeyFunc: KeyByRoute(KeyByIP)}))
+	srv.Get("/b", func(ctx web.Ctx) {}, RateLimit(RateLimitOption{Limiter: limiter, KeyFunc: KeyByRoute(KeyByIP)}))
+
+	rec := serve(srv, httptest.NewRequest(http.MethodGet, "/a", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", rec.Header().Get("RateLimit-Reset"))
+
+	rec = serve(srv, httptest.NewRequest(http.MethodGet, "/a", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+	rec = serve(srv, httptest.NewRequest(http.MethodGet, "/b", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRateLimit_KeyByPrincipal(t *testing.T) {
+	srv := web.New(web.Option{})
+	srv.Use(
+		Auth(AuthOption{Authenticator: auth.APIKey("X-API-Key", auth.StaticAPIKeys(map[string]*auth.Principal{
+			"k-alice": {Subject: "alice"},
+			"k-bob":   {Subject: "bob"},
+		})), Optional: true}),
+		RateLimit(RateLimitOption{
+			Limiter: ratelimit.New(ratelimit.Option{Rate: ratelimit.PerMinute(1)}),
+			KeyFunc: KeyByPrincipal,
+		}),
+	)
+	srv.Get("/a", func(ctx web.Ctx) {})
+	get := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/a", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		return serve(srv, req).Code
+	}
+
+	assert.Equal(t, http.StatusOK, get("k-alice"))
+	assert.Equal(t, http.StatusTooManyRequests, get("k-alice"))
+	assert.Equal(t, http.StatusOK, get("k-bob"))
+	assert.Equal(t, http.StatusOK, get(""))
+	assert.Equal(t, http.StatusOK, get(""))
+}
+
+func TestAuth(t *testing.T) {
+	key := auth.Key{Algorithm: "HS256", Key: []byte("secret")}
+	v, err := auth.NewJWTVerifier(auth.JWTOption{Keys: []auth.Key{key}})
+	require.NoError(t, err)
+	srv := web.New(web.Option{})
+	srv.Use(Auth(AuthOption{Authenticator: auth.Bearer(v), Optional: true, Challenge: `Bearer realm="api"`}))
+	srv.Get("/me", func(ctx web.Ctx) {
+		_ = ctx.String(GetPrincipal(ctx).Subject + " " + auth.PrincipalFromContext(ctx.Request().Context()).Subject)
+	}, RequireRoles("user", "admin"))
+	srv.Get("/admin", func(ctx web.Ctx) {}, RequireRoles("admin"), RequireScopes("write"))
+	srv.Get("/public", func(ctx web.Ctx) {})
+
+	token, err := auth.SignJWT(key, map[string]any{"sub": "alice", "roles": []string{"user"}})
+	require.NoError(t, err)
+	get := func(path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return serve(srv, req)
+	}
+
+	rec := get("/me", "Bearer "+token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "alice alice", rec.Body.String())
+	assert.Equal(t, http.StatusForbidden, get("/admin", "Bearer "+token).Code)
+	assert.Equal(t, http.StatusUnauthorized, get("/me", "").Code)
+	assert.Equal(t, http.StatusOK, get("/public", "").Code)
+
+	rec = get("/public", "Bearer "+token+"x")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, `Bearer realm="api"`, rec.Header().Get("WWW-Authenticate"))
+}
+
+func TestCSRF(t *testing.T) {
+	srv := web.New(web.Option{})
+	srv.Use(CSRF(CSRFOption{TrustedOrigins: []string{"app.example.com"}}))
+	srv.Get("/form", func(ctx web.Ctx) {
+		_ = ctx.String(CSRFToken(ctx))
+	})
+	srv.Post("/submit", func(ctx web.Ctx) {})
+
+	rec := serve(srv, httptest.NewRequest(http.MethodGet, "/form", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	cookie := rec.Result().Cookies()[0]
+	token := rec.Body.String()
+	assert.True(t, cookie.HttpOnly)
+
+	// Tokens are masked differently on every request but stay valid.
+	req := httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.AddCookie(cookie)
+	rec = serve(srv, req)
+	assert.Empty(t, rec.Result().Cookies())
+	assert.NotEqual(t, token, rec.Body.String())
+	token2 := rec.Body.String()
+
+	post := func(form, header, origin string) int {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		if header != "" {
+			req.Header.Set("X-CSRF-Token", header)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		return serve(srv, req).Code
+	}
+	assert.Equal(t, http.StatusOK, post("_csrf="+token, "", ""))
+	assert.Equal(t, http.StatusOK, post("", token2, "https://app.example.com"))
+	assert.Equal(t, http.StatusOK, post("", token2, "http://example.com"))
+	assert.Equal(t, http.StatusForbidden, post("", "", ""))
+	assert.Equal(t, http.StatusForbidden, post("", token2[:len(token2)-2]+"AA", ""))
+	assert.Equal(t, http.StatusForbidden, post("", token2, "https://evil.example"))
+
+	// Vary values set by other middleware are kept.
+	srv = web.New(web.Option{})
+	srv.Use(CORS(CORSOption{AllowOrigins: []string{"https://app.example.com"}}), CSRF(CSRFOption{}))
+	srv.Get("/form", func(ctx web.Ctx) {})
+	req = httptest.NewRequest(http.MethodGet, "/form", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = serve(srv, req)
+	assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+	assert.Contains(t, rec.Header().Values("Vary"), "Cookie")
+}
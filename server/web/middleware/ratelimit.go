@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/ratelimit"
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+// KeyFunc returns the key a request is limited by. An empty key skips the
+// limit.
+type KeyFunc func(ctx web.Ctx) string
+
+// KeyByIP limits each client address, as resolved by RealIP.
+func KeyByIP(ctx web.Ctx) string {
+	return ClientIP(ctx)
+}
+
+// KeyByHeader limits each value of a request header. Clients choose the
+// value, so limits per user should use KeyByPrincipal instead.
+func KeyByHeader(name string) KeyFunc {
+	return func(ctx web.Ctx) string {
+		return ctx.GetHeader(name)
+	}
+}
+
+// KeyByPrincipal limits each principal authenticated by Auth, which must
+// run first. Anonymous requests are not limited by it.
+func KeyByPrincipal(ctx web.Ctx) string {
+	p := GetPrincipal(ctx)
+	if p == nil {
+		return ""
+	}
+	return p.Method + ":" + p.Subject
+}
+
+// KeyByRoute limits each route separately, by the key of f within it.
+func KeyByRoute(f KeyFunc) KeyFunc {
+	return func(ctx web.Ctx) string {
+		key := f(ctx)
+		if key == "" {
+			return ""
+		}
+		route := ctx.Request().URL.Path
+		if r := mux.CurrentRoute(ctx.Request()); r != nil {
+			if tpl, err := r.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		return ctx.Request().Method + " " + route + ":" + key
+	}
+}
+
+type RateLimitOption struct {
+	Limiter *ratelimit.Limiter
+	// KeyFunc defaults to KeyByIP.
+	KeyFunc KeyFunc
+	// Logger receives store errors, on which requests are let through. It
+	// defaults to logger.Default().
+	Logger logger.Logger
+}
+
+// RateLimit answers 429 to requests over the limit and reports the limit
+// in RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers,
+// adding Retry-After to denied requests.
+func RateLimit(opt RateLimitOption) web.MiddlewareHandler {
+	if opt.KeyFunc == nil {
+		opt.KeyFunc = KeyByIP
+	}
+	return func(ctx web.Ctx, next func()) {
+		key := opt.KeyFunc(ctx)
+		if key == "" {
+			next()
+			return
+		}
+		res, err := opt.Limiter.Allow(ctx, key)
+		if err != nil {
+			log := opt.Logger
+			if log == nil {
+				log = logger.Default()
+			}
+			log.Errorf("middleware: rate limit %q: %v", key, err)
+			next()
+			return
+		}
+
+		h := ctx.Writer().Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", seconds(res.Reset))
+		if !res.Allowed {
+			h.Set("Retry-After", seconds(res.RetryAfter))
+			ctx.Error(web.NewHTTPError(http.StatusTooManyRequests))
+			return
+		}
+		next()
+	}
+}
+
+// seconds formats d as whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
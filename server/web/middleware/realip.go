@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+const clientIPKey = "_hyper/clientIP"
+
+type RealIPOption struct {
+	// TrustedProxies lists the addresses or CIDR ranges of proxies whose
+	// forwarding headers are believed, such as "10.0.0.0/8".
+	TrustedProxies []string
+	// Headers are read in order and default to X-Forwarded-For and
+	// X-Real-IP.
+	Headers []string
+}
+
+// RealIP resolves the client address of requests arriving through trusted
+// proxies, walking X-Forwarded-For from the right and stopping at the
+// first untrusted hop. The result is stored for ClientIP and replaces the
+// request's RemoteAddr. It panics on an invalid trusted proxy.
+func RealIP(opt RealIPOption) web.MiddlewareHandler {
+	if len(opt.Headers) == 0 {
+		opt.Headers = []string{"X-Forwarded-For", "X-Real-IP"}
+	}
+	trusted := make([]netip.Prefix, 0, len(opt.TrustedProxies))
+	for _, p := range opt.TrustedProxies {
+		prefix, err := parsePrefix(p)
+		if err != nil {
+			panic("middleware: invalid trusted proxy " + p)
+		}
+		trusted = append(trusted, prefix)
+	}
+	isTrusted := func(addr netip.Addr) bool {
+		for _, p := range trusted {
+			if p.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(ctx web.Ctx, next func()) {
+		req := ctx.Request()
+		remote, err := netip.ParseAddr(remoteHost(req))
+		if err != nil || !isTrusted(remote) {
+			next()
+			return
+		}
+
+		ip := remote
+	headers:
+		for _, h := range opt.Headers {
+			values := req.Header.Values(h)
+			if len(values) == 0 {
+				continue
+			}
+			hops := strings.Split(strings.Join(values, ","), ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+				if err != nil {
+					break headers
+				}
+				ip = addr.Unmap()
+				if !isTrusted(ip) {
+					break headers
+				}
+			}
+			break
+		}
+
+		ctx.Set(clientIPKey, ip.String())
+		r := req.Clone(req.Context())
+		r.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+		ctx.SetRequest(r)
+		next()
+	}
+}
+
+// ClientIP returns the address resolved by RealIP, or the host of the
+// request's RemoteAddr.
+func ClientIP(ctx web.Ctx) string {
+	if ip := ctx.GetString(clientIPKey); ip != "" {
+		return ip
+	}
+	return remoteHost(ctx.Request())
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		return p.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
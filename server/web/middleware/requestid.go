@@ -0,0 +1,69 @@
+// Package middleware provides common web.MiddlewareHandler implementations.
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+const (
+	DefaultRequestIDHeader = "X-Request-ID"
+
+	requestIDKey = "_hyper/requestID"
+)
+
+type RequestIDOption struct {
+	// Header carries the request ID in both directions and defaults to
+	// X-Request-ID.
+	Header string
+	// Generator returns new request IDs and defaults to 16 random bytes in
+	// hex.
+	Generator func() string
+}
+
+// RequestID reuses the request ID sent by the client, or generates one,
+// stores it for GetRequestID and echoes it in the response.
+func RequestID(opt RequestIDOption) web.MiddlewareHandler {
+	if opt.Header == "" {
+		opt.Header = DefaultRequestIDHeader
+	}
+	if opt.Generator == nil {
+		opt.Generator = newRequestID
+	}
+	return func(ctx web.Ctx, next func()) {
+		id := ctx.GetHeader(opt.Header)
+		if !validRequestID(id) {
+			id = opt.Generator()
+		}
+		ctx.Set(requestIDKey, id)
+		ctx.Header(opt.Header, id)
+		next()
+	}
+}
+
+// GetRequestID returns the request ID set by RequestID, if any.
+func GetRequestID(ctx web.Ctx) string {
+	return ctx.GetString(requestIDKey)
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID rejects client IDs that are too long or could forge log
+// lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+// SecureHeadersOption sets response headers hardening browsers. Empty
+// fields are not sent.
+type SecureHeadersOption struct {
+	ContentTypeOptions      string
+	FrameOptions            string
+	ReferrerPolicy          string
+	ContentSecurityPolicy   string
+	PermissionsPolicy       string
+	CrossOriginOpenerPolicy string
+	// HSTSMaxAge enables Strict-Transport-Security on TLS requests.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+}
+
+var DefaultSecureHeadersOption = SecureHeadersOption{
+	ContentTypeOptions:      "nosniff",
+	FrameOptions:            "SAMEORIGIN",
+	ReferrerPolicy:          "strict-origin-when-cross-origin",
+	CrossOriginOpenerPolicy: "same-origin",
+}
+
+func SecureHeaders(opt SecureHeadersOption) web.MiddlewareHandler {
+	headers := map[string]string{
+		"X-Content-Type-Options":     opt.ContentTypeOptions,
+		"X-Frame-Options":            opt.FrameOptions,
+		"Referrer-Policy":            opt.ReferrerPolicy,
+		"Content-Security-Policy":    opt.ContentSecurityPolicy,
+		"Permissions-Policy":         opt.PermissionsPolicy,
+		"Cross-Origin-Opener-Policy": opt.CrossOriginOpenerPolicy,
+	}
+	for k, v := range headers {
+		if v == "" {
+			delete(headers, k)
+		}
+	}
+	var hsts string
+	if opt.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(opt.HSTSMaxAge.Seconds()))
+		if opt.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if opt.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	return func(ctx web.Ctx, next func()) {
+		h := ctx.Writer().Header()
+		for k, v := range headers {
+			h.Set(k, v)
+		}
+		if hsts != "" && (ctx.Request().TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https") {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		next()
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/hyper-micro/hyper/server/web"
+)
+
+// Timeout cancels the request context after d. Handlers must observe the
+// cancellation, such as through database or HTTP client calls made with
+// the context; if the deadline passed before they responded, 503 is
+// answered.
+func Timeout(d time.Duration) web.MiddlewareHandler {
+	return func(ctx web.Ctx, next func()) {
+		req := ctx.Request()
+		tctx, cancel := context.WithTimeout(req.Context(), d)
+		defer cancel()
+		ctx.SetRequest(req.WithContext(tctx))
+		next()
+
+		if stdErrors.Is(tctx.Err(), context.DeadlineExceeded) && !ctx.Written() {
+			ctx.Error(web.NewHTTPError(http.StatusServiceUnavailable, "request timeout"))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/hyper-micro/hyper/logger"
+)
+
+// PanicHandler reports a panic recovered from a handler or middleware,
+// such as to an error tracker. The response is written after it returns,
+// unless it already wrote one, with the server's ErrorHandler.
+type PanicHandler func(ctx Ctx, p any, stack []byte)
+
+// recoverPanic must be deferred directly. It logs the panic and answers
+// 500 if the response has not started.
+func (s *Server) recoverPanic(c *ctx) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	if p == http.ErrAbortHandler {
+		panic(p)
+	}
+
+	stack := debug.Stack()
+	s.logger().Errorf("web: panic serving %s %s: %v\n%s", c.r.Method, c.r.URL.Path, p, stack)
+	if s.PanicHandler != nil {
+		s.PanicHandler(c, p, stack)
+	}
+	s.renderError(c, NewHTTPError(http.StatusInternalServerError))
+	c.Abort()
+}
+
+func (s *Server) logger() logger.Logger {
+	if s.Logger != nil {
+		return s.Logger
+	}
+	return logger.Default()
+}
@@ -0,0 +1,52 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_RecoverPanic(t *testing.T) {
+	log := loggertest.New()
+	var reported any
+	srv := New(Option{
+		Logger: log,
+		PanicHandler: func(ctx Ctx, p any, stack []byte) {
+			reported = p
+		},
+	})
+	var after bool
+	srv.Use(func(ctx Ctx, next func()) {
+		next()
+		after = true
+	})
+	srv.Get("/panic", func(ctx Ctx) {
+		panic("boom")
+	})
+	srv.Get("/written", func(ctx Ctx) {
+		_ = ctx.ResponseWithStatus(http.StatusAccepted, nil)
+		panic("late")
+	})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "boom", reported)
+	assert.True(t, after)
+	log.AssertLogged(t, logger.ErrorLevel, "web: panic serving GET /panic: boom")
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/written", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		srv.Get("/abort", func(ctx Ctx) {
+			panic(http.ErrAbortHandler)
+		})
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+}
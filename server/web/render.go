@@ -0,0 +1,177 @@
+package web
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyper-micro/hyper/internal/json"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ErrNoHTMLRenderer is returned by Ctx.HTML on a server without
+// Option.HTML.
+var ErrNoHTMLRenderer = errors.New("web: no HTML renderer configured")
+
+func (c *ctx) XML(data any) error {
+	b, err := xml.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.Header("Content-Type", "application/xml; charset=utf-8")
+	return c.Response(append([]byte(xml.Header), b...))
+}
+
+func (c *ctx) Msgpack(data any) error {
+	b, err := msgpack.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.Header("Content-Type", MIMEMsgpack)
+	return c.Response(b)
+}
+
+func (c *ctx) HTML(name string, data any) error {
+	if c.srv.HTML == nil {
+		return ErrNoHTMLRenderer
+	}
+	var buf bytes.Buffer
+	if err := c.srv.HTML.Render(&buf, name, data); err != nil {
+		return err
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	return c.Response(buf.Bytes())
+}
+
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+func (c *ctx) JSONP(data any) error {
+	callback := c.QueryString("callback")
+	if callback == "" {
+		return c.Json(data)
+	}
+	if !jsonpCallback.MatchString(callback) {
+		return NewHTTPError(http.StatusBadRequest, "invalid callback")
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	c.Header("Content-Type", "application/javascript; charset=utf-8")
+	c.Header("X-Content-Type-Options", "nosniff")
+	// The leading comment keeps the response from starting with bytes the
+	// caller controls.
+	return c.Response([]byte("/**/ typeof " + callback + " === 'function' && " + callback + "(" + string(b) + ");"))
+}
+
+func (c *ctx) NoContent() {
+	c.Status(http.StatusNoContent)
+}
+
+func (c *ctx) Redirect(code int, location string) {
+	http.Redirect(c.w, c.r, location, code)
+}
+
+func (c *ctx) File(name string) {
+	http.ServeFile(c.w, c.r, name)
+}
+
+func (c *ctx) FileFS(fsys fs.FS, name string) {
+	http.ServeFileFS(c.w, c.r, fsys, name)
+}
+
+func (c *ctx) Attachment(name, filename string) {
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.File(name)
+}
+
+func (c *ctx) Stream(step func(w io.Writer) bool) bool {
+	c.Status(http.StatusOK)
+	rc := http.NewResponseController(c.w)
+	for {
+		select {
+		case <-c.Done():
+			return true
+		default:
+		}
+		keepOpen := step(c.w)
+		_ = rc.Flush()
+		if !keepOpen {
+			return false
+		}
+	}
+}
+
+func (c *ctx) Negotiate(data any) error {
+	switch c.NegotiateFormat(MIMEJSON, MIMEXML, MIMEXML2, MIMEMsgpack, MIMEMsgpack2) {
+	case MIMEJSON:
+		return c.Json(data)
+	case MIMEXML, MIMEXML2:
+		return c.XML(data)
+	case MIMEMsgpack, MIMEMsgpack2:
+		return c.Msgpack(data)
+	}
+	return NewHTTPError(http.StatusNotAcceptable)
+}
+
+// NegotiateFormat returns the offered media type the Accept header
+// prefers, the first offer if there is no Accept header, or "" if none is
+// acceptable.
+func (c *ctx) NegotiateFormat(offered ...string) string {
+	accept := c.r.Header.Get("Accept")
+	if len(offered) == 0 {
+		return ""
+	}
+	if accept == "" {
+		return offered[0]
+	}
+
+	type mediaRange struct {
+		typ, subtype string
+		q            float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, _ := strings.Cut(part, ";")
+		typ, subtype, _ := strings.Cut(strings.ToLower(strings.TrimSpace(mediaType)), "/")
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(p), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+		if typ != "" && subtype != "" {
+			ranges = append(ranges, mediaRange{typ: typ, subtype: subtype, q: q})
+		}
+	}
+	// More specific ranges take precedence over wildcards of equal weight.
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return strings.Count(ranges[i].typ+ranges[i].subtype, "*") < strings.Count(ranges[j].typ+ranges[j].subtype, "*")
+	})
+
+	for _, r := range ranges {
+		if r.q <= 0 {
+			break
+		}
+		for _, offer := range offered {
+			typ, subtype, _ := strings.Cut(offer, "/")
+			if (r.typ == "*" || r.typ == typ) && (r.subtype == "*" || r.subtype == subtype) {
+				return offer
+			}
+		}
+	}
+	return ""
+}
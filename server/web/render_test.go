@@ -0,0 +1,111 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type renderItem struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func TestCtx_Render(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	write("layout.html", `{{define "layout"}}<main>{{template "content" .}}</main>{{end}}`)
+	write("users/show.html", `{{template "layout" .}}{{define "content"}}{{upper .Name}}{{end}}`)
+	write("file.txt", "file content")
+	tpl, err := NewTemplates(TemplateOption{
+		Dir:    dir,
+		Funcs:  map[string]any{"upper": strings.ToUpper},
+		Reload: true,
+	})
+	require.NoError(t, err)
+
+	srv := New(Option{HTML: tpl})
+	srv.Get("/html", WithError(func(ctx Ctx) error {
+		return ctx.HTML("users/show.html", renderItem{Name: "<alice>"})
+	}))
+	srv.Get("/negotiate", WithError(func(ctx Ctx) error {
+		return ctx.Negotiate(renderItem{Name: "alice"})
+	}))
+	srv.Get("/jsonp", WithError(func(ctx Ctx) error {
+		return ctx.JSONP(renderItem{Name: "alice"})
+	}))
+	srv.Get("/redirect", func(ctx Ctx) {
+		ctx.Redirect(http.StatusFound, "/html")
+	})
+	srv.Delete("/item", func(ctx Ctx) {
+		ctx.NoContent()
+	})
+	srv.Get("/download", func(ctx Ctx) {
+		ctx.Attachment(filepath.Join(dir, "file.txt"), "report 1.txt")
+	})
+	srv.Get("/fs", func(ctx Ctx) {
+		ctx.FileFS(fstest.MapFS{"a.css": {Data: []byte("body{}")}}, "a.css")
+	})
+	srv.Get("/stream", func(ctx Ctx) {
+		n := 0
+		ctx.Stream(func(w io.Writer) bool {
+			n++
+			_, _ = io.WriteString(w, "chunk;")
+			return n < 3
+		})
+	})
+	do := func(method, path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/html")
+	assert.Equal(t, "<main>&lt;ALICE&gt;</main>", rec.Body.String())
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	// Reload picks up edits without a restart.
+	write("users/show.html", `{{define "content"}}bye{{end}}{{template "layout" .}}`)
+	assert.Equal(t, "<main>bye</main>", do(http.MethodGet, "/html").Body.String())
+	write("users/show.html", `{{template "missing" .}}`)
+	assert.Equal(t, http.StatusInternalServerError, do(http.MethodGet, "/html").Code)
+
+	assert.JSONEq(t, `{"name":"alice"}`, do(http.MethodGet, "/negotiate").Body.String())
+	rec = do(http.MethodGet, "/negotiate", "Accept", "text/html, application/xml;q=0.9, */*;q=0.1")
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<renderItem><name>alice</name></renderItem>`, rec.Body.String())
+	rec = do(http.MethodGet, "/negotiate", "Accept", "application/x-msgpack")
+	assert.Equal(t, "application/msgpack", rec.Header().Get("Content-Type"))
+	assert.Equal(t, http.StatusNotAcceptable, do(http.MethodGet, "/negotiate", "Accept", "text/html").Code)
+
+	assert.Equal(t, `/**/ typeof cb.done === 'function' && cb.done({"name":"alice"});`, do(http.MethodGet, "/jsonp?callback=cb.done").Body.String())
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "/jsonp?callback=alert(1)").Code)
+
+	rec = do(http.MethodGet, "/redirect")
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "/html", rec.Header().Get("Location"))
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/item").Code)
+
+	rec = do(http.MethodGet, "/download")
+	assert.Equal(t, "file content", rec.Body.String())
+	assert.Equal(t, `attachment; filename="report 1.txt"`, rec.Header().Get("Content-Disposition"))
+	rec = do(http.MethodGet, "/fs")
+	assert.Equal(t, "body{}", rec.Body.String())
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/css"))
+
+	rec = do(http.MethodGet, "/stream")
+	assert.Equal(t, "chunk;chunk;chunk;", rec.Body.String())
+	assert.True(t, rec.Flushed)
+}
@@ -1,135 +1,245 @@
 package web
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"net/http"
+	"net/url"
 
 	"github.com/gorilla/mux"
+	"github.com/spf13/cast"
 )
 
 type Router interface {
-	Get(path string, f Handler)
-	Head(path string, f Handler)
-	Post(path string, f Handler)
-	Put(path string, f Handler)
-	Patch(path string, f Handler)
-	Delete(path string, f Handler)
-	Connect(path string, f Handler)
-	Options(path string, f Handler)
-	Trace(path string, f Handler)
-	Any(path string, f Handler)
-	Use(fs ...MiddlewareHandler)
-	PathPrefix(prefix string) *router
-	HostPrefix(host string) *router
+	Get(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Head(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Post(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Put(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Patch(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Delete(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Connect(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Options(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Trace(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Any(path string, f Handler, mws ...MiddlewareHandler) *Route
+	Handle(methods []string, path string, f Handler, mws ...MiddlewareHandler) *Route
+	Use(mws ...MiddlewareHandler)
+	Group(prefix string, mws ...MiddlewareHandler) *Group
+	PathPrefix(prefix string) *Group
+	HostPrefix(host string) *Group
+	Static(prefix, dir string, opt ...StaticOption) *Route
+	StaticFS(prefix string, fsys fs.FS, opt ...StaticOption) *Route
 }
 
 type Handler func(ctx Ctx)
 
 type MiddlewareHandler func(ctx Ctx, next func())
 
-type router struct {
-	r   *mux.Router
-	srv *Server
+var anyMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// Group is a set of routes sharing a path or host prefix and middleware.
+// Middleware added with Use runs, outermost first, for the routes of the
+// group and of its subgroups, whether they were registered before or after.
+type Group struct {
+	r      *mux.Router
+	srv    *Server
+	parent *Group
+	mws    []MiddlewareHandler
+}
+
+var _ Router = (*Group)(nil)
+
+func newGroup(srv *Server, parent *Group, r *mux.Router) *Group {
+	return &Group{
+		srv:    srv,
+		parent: parent,
+		r:      r,
+	}
 }
 
-func newRouter(srv *Server, r *mux.Router) *router {
-	return &router{
-		srv: srv,
-		r:   r,
+// chain returns the middleware of g and its ancestors followed by mws.
+func (g *Group) chain(mws []MiddlewareHandler) []MiddlewareHandler {
+	var groups []*Group
+	for p := g; p != nil; p = p.parent {
+		groups = append(groups, p)
+	}
+	var chain []MiddlewareHandler
+	for i := len(groups) - 1; i >= 0; i-- {
+		chain = append(chain, groups[i].mws...)
 	}
+	return append(chain, mws...)
 }
 
-func (r *router) wrapHandler(f Handler) func(w http.ResponseWriter, req *http.Request) {
+func (g *Group) wrapHandler(f Handler, mws []MiddlewareHandler) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
-		ctx := makeContext(r.srv, w, req)
-		if ctx.IsAbort() {
-			return
-		}
-		f(ctx)
-	}
-}
+		ctx := makeContext(g.srv, w, req)
+		chain := g.chain(mws)
 
-func (r *router) wrapMiddleware(f MiddlewareHandler) mux.MiddlewareFunc {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			ctx := makeContext(r.srv, w, req)
+		var next func(i int)
+		next = func(i int) {
 			if ctx.IsAbort() {
 				return
 			}
-			f(ctx, func() {
-				next.ServeHTTP(w, req)
+			defer g.srv.recoverPanic(ctx)
+			if i == len(chain) {
+				f(ctx)
+				return
+			}
+			chain[i](ctx, func() {
+				next(i + 1)
 			})
-		})
+		}
+		next(0)
 	}
 }
 
-func (r *router) Get(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodGet)
+func (g *Group) Handle(methods []string, path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return &Route{r: g.r.HandleFunc(path, g.wrapHandler(f, mws)).Methods(methods...)}
 }
 
-func (r *router) Head(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodHead)
+func (g *Group) Get(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodGet}, path, f, mws...)
 }
 
-func (r *router) Post(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodPost)
+func (g *Group) Head(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodHead}, path, f, mws...)
 }
 
-func (r *router) Put(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodPut)
+func (g *Group) Post(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodPost}, path, f, mws...)
 }
 
-func (r *router) Patch(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodPatch)
+func (g *Group) Put(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodPut}, path, f, mws...)
 }
 
-func (r *router) Delete(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodDelete)
+func (g *Group) Patch(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodPatch}, path, f, mws...)
 }
 
-func (r *router) Connect(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodConnect)
+func (g *Group) Delete(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodDelete}, path, f, mws...)
 }
 
-func (r *router) Options(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodOptions)
+func (g *Group) Connect(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodConnect}, path, f, mws...)
 }
 
-func (r *router) Trace(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(http.MethodTrace)
+func (g *Group) Options(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodOptions}, path, f, mws...)
 }
 
-func (r *router) Any(path string, f Handler) {
-	r.r.HandleFunc(path, r.wrapHandler(f)).Methods(
-		http.MethodGet,
-		http.MethodHead,
-		http.MethodPost,
-		http.MethodPut,
-		http.MethodPatch,
-		http.MethodDelete,
-		http.MethodConnect,
-		http.MethodOptions,
-		http.MethodTrace,
-	)
+func (g *Group) Trace(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle([]string{http.MethodTrace}, path, f, mws...)
+}
+
+func (g *Group) Any(path string, f Handler, mws ...MiddlewareHandler) *Route {
+	return g.Handle(anyMethods, path, f, mws...)
+}
+
+// Use appends middleware to the group. It is not safe to call once the
+// server is serving.
+func (g *Group) Use(mws ...MiddlewareHandler) {
+	g.mws = append(g.mws, mws...)
+}
+
+// Group returns a subgroup for routes under prefix, running mws after the
+// middleware of g.
+func (g *Group) Group(prefix string, mws ...MiddlewareHandler) *Group {
+	ng := g.PathPrefix(prefix)
+	ng.Use(mws...)
+	return ng
 }
 
-func (r *router) Use(fs ...MiddlewareHandler) {
-	var nfs []mux.MiddlewareFunc
-	for _, f := range fs {
-		nfs = append(nfs, r.wrapMiddleware(f))
+func (g *Group) PathPrefix(prefix string) *Group {
+	return newGroup(g.srv, g, g.r.PathPrefix(prefix).Subrouter())
+}
+
+func (g *Group) HostPrefix(host string) *Group {
+	return newGroup(g.srv, g, g.r.Host(host).Subrouter())
+}
+
+// URLFor builds the URL of the route registered under name, filling its
+// variables from pairs of name and value, such as
+// URLFor("user.show", "id", 5). Route names are shared by all groups of a
+// server.
+func (g *Group) URLFor(name string, pairs ...any) (string, error) {
+	route := g.r.Get(name)
+	if route == nil {
+		return "", fmt.Errorf("web: no route named %q", name)
+	}
+	if len(pairs)%2 != 0 {
+		return "", errors.New("web: URLFor expects pairs of variable name and value")
+	}
+	vars := make([]string, len(pairs))
+	for i, p := range pairs {
+		vars[i] = cast.ToString(p)
+	}
+	var (
+		u   *url.URL
+		err error
+	)
+	if tpl, _ := route.GetHostTemplate(); tpl != "" {
+		u, err = route.URL(vars...)
+	} else {
+		u, err = route.URLPath(vars...)
 	}
-	r.r.Use(nfs...)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Route is a registered route.
+type Route struct {
+	r *mux.Route
+}
+
+// Name names the route for URLFor and route listings. Names must be unique
+// per server; a later route reusing a name replaces the earlier one.
+func (r *Route) Name(name string) *Route {
+	r.r.Name(name)
+	return r
 }
 
-func (r *router) PathPrefix(prefix string) *router {
-	nr := r.r.PathPrefix(prefix).Subrouter()
-	return newRouter(r.srv, nr)
+type RouteInfo struct {
+	Name    string   `json:"name,omitempty"`
+	Methods []string `json:"methods,omitempty"`
+	Path    string   `json:"path"`
 }
 
-func (r *router) HostPrefix(host string) *router {
-	nr := r.r.Host(host).Subrouter()
-	return newRouter(r.srv, nr)
+// Routes lists the registered routes in registration order.
+func (g *Group) Routes() []RouteInfo {
+	var routes []RouteInfo
+	_ = g.r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		if route.GetHandler() == nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		routes = append(routes, RouteInfo{
+			Name:    route.GetName(),
+			Methods: methods,
+			Path:    tpl,
+		})
+		return nil
+	})
+	return routes
 }
 
-func (r *router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	r.r.ServeHTTP(w, req)
+func (g *Group) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	g.r.ServeHTTP(w, req)
 }
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_Middleware(t *testing.T) {
+	srv := New(Option{})
+	var calls []string
+	mw := func(name string) MiddlewareHandler {
+		return func(ctx Ctx, next func()) {
+			calls = append(calls, name)
+			next()
+		}
+	}
+	handler := func(ctx Ctx) {
+		calls = append(calls, "handler")
+	}
+
+	srv.Get("/", handler)
+	api := srv.Group("/api", mw("api"))
+	api.Get("/users", handler, mw("route"))
+	api.Group("/admin", mw("admin")).Get("/stats", handler)
+	api.Get("/denied", handler, func(ctx Ctx, next func()) {
+		ctx.Abort()
+	})
+	srv.Use(mw("root"))
+
+	for path, want := range map[string][]string{
+		"/":                {"root", "handler"},
+		"/api/users":       {"root", "api", "route", "handler"},
+		"/api/admin/stats": {"root", "api", "admin", "handler"},
+		"/api/denied":      {"root", "api"},
+	} {
+		calls = nil
+		srv.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, want, calls, path)
+	}
+}
+
+func TestGroup_URLFor(t *testing.T) {
+	srv := New(Option{})
+	users := srv.Group("/users")
+	users.Get("/{id:[0-9]+}", func(ctx Ctx) {}).Name("user.show")
+
+	u, err := srv.URLFor("user.show", "id", 5)
+	require.NoError(t, err)
+	assert.Equal(t, "/users/5", u)
+	u, err = users.URLFor("user.show", "id", "7")
+	require.NoError(t, err)
+	assert.Equal(t, "/users/7", u)
+
+	_, err = srv.URLFor("user.show", "id", "x")
+	assert.Error(t, err)
+	_, err = srv.URLFor("user.show", "id")
+	assert.Error(t, err)
+	_, err = srv.URLFor("missing")
+	assert.ErrorContains(t, err, "missing")
+
+	assert.Equal(t, []RouteInfo{{Name: "user.show", Methods: []string{http.MethodGet}, Path: "/users/{id:[0-9]+}"}}, srv.Routes())
+}
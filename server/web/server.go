@@ -6,9 +6,13 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/metrics"
+	"github.com/hyper-micro/hyper/tracing"
 )
 
 type Config struct {
@@ -30,23 +34,65 @@ type Option struct {
 	ErrorLog           *log.Logger
 	BaseContext        func(net.Listener) context.Context
 	ConnContext        func(ctx context.Context, c net.Conn) context.Context
+	// Logger receives recovered panics and defaults to logger.Default().
+	Logger       logger.Logger
+	PanicHandler PanicHandler
+	// ErrorHandler renders handler errors and defaults to
+	// DefaultErrorHandler.
+	ErrorHandler ErrorHandler
+	// NotFound and MethodNotAllowed answer requests matching no route, or
+	// no route for their method. They default to rendering a 404 or 405
+	// HTTPError, and run after the root middleware.
+	NotFound         Handler
+	MethodNotAllowed Handler
+	// Locale selects the language of validation messages for requests
+	// without a supported Accept-Language, such as "zh". It defaults to
+	// DefaultLocale.
+	Locale string
+	// Metrics, when set, records request count and latency per route.
+	Metrics *metrics.Registry
+	// Tracer, when set, starts a server span for every request, continuing
+	// the trace of the caller's traceparent header.
+	Tracer *tracing.Tracer
+	// Session, when set, stores the sessions of Ctx.Session.
+	Session *SessionOption
+	// HTML renders the templates of Ctx.HTML, such as *Templates.
+	HTML HTMLRenderer
 }
 
+// rootGroup lets Server embed its root Group without the field name
+// shadowing the Group method.
+type rootGroup = Group
+
 type Server struct {
 	Option
 
-	*router
+	*rootGroup
 	srv      *http.Server
 	handlers []HandlerFunc
+	ln       net.Listener
+	lnMu     sync.Mutex
+	metrics  *serverMetrics
 }
 
 func New(opt Option) *Server {
+	if opt.Session != nil {
+		opt.Session = opt.Session.withDefaults()
+	}
 	srv := &Server{Option: opt}
 	rr := mux.NewRouter()
-	srv.router = newRouter(srv, rr)
+	srv.rootGroup = newGroup(srv, nil, rr)
+	if opt.NotFound == nil {
+		opt.NotFound = notFound
+	}
+	if opt.MethodNotAllowed == nil {
+		opt.MethodNotAllowed = methodNotAllowed
+	}
+	rr.NotFoundHandler = srv.rootGroup.wrapHandler(opt.NotFound, nil)
+	rr.MethodNotAllowedHandler = srv.rootGroup.wrapHandler(opt.MethodNotAllowed, nil)
 	srv.srv = &http.Server{
 		Addr:              opt.Addr,
-		Handler:           srv.router,
+		Handler:           srv.rootGroup,
 		TLSConfig:         opt.TLSConfig,
 		ReadTimeout:       opt.ReadTimeout,
 		ReadHeaderTimeout: opt.ReadHeaderTimeout,
@@ -60,10 +106,63 @@ func New(opt Option) *Server {
 		ConnContext:       opt.ConnContext,
 	}
 
+	if opt.Metrics != nil {
+		srv.metrics = newServerMetrics(opt.Metrics)
+	}
+	if opt.Metrics != nil || opt.Tracer != nil {
+		rr.Use(recordRoute)
+		srv.srv.Handler = srv.instrument(srv.rootGroup)
+	}
+
 	return srv
 }
 
+func (s *Server) Listen(ctx context.Context) error {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln != nil {
+		return nil
+	}
+	addr := s.srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := new(net.ListenConfig).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	return nil
+}
+
+// SetListener makes the server serve on ln, such as a listener inherited
+// from a parent process, instead of binding its own. It has no effect once
+// the server is listening.
+func (s *Server) SetListener(ln net.Listener) {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln == nil {
+		s.ln = ln
+	}
+}
+
+func (s *Server) Listener() net.Listener {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.ln
+}
+
+func (s *Server) Listening() bool {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.ln != nil
+}
+
 func (s *Server) Run() error {
+	if err := s.Listen(context.Background()); err != nil {
+		return err
+	}
+
 	if len(s.handlers) > 0 {
 		h := s.srv.Handler
 		for i := len(s.handlers) - 1; i >= 0; i-- {
@@ -81,10 +180,10 @@ func (s *Server) Run() error {
 	)
 	for {
 		if s.Option.CertFile != "" && s.Option.KeyFile != "" {
-			srvErr = s.srv.ListenAndServeTLS(s.Option.CertFile, s.Option.KeyFile)
+			srvErr = s.srv.ServeTLS(s.ln, s.Option.CertFile, s.Option.KeyFile)
 			break
 		}
-		srvErr = s.srv.ListenAndServe()
+		srvErr = s.srv.Serve(s.ln)
 		break
 	}
 	if srvErr == http.ErrServerClosed {
@@ -101,6 +200,10 @@ func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
+	return s.ShutdownContext(ctx)
+}
+
+func (s *Server) ShutdownContext(ctx context.Context) error {
 	return s.srv.Shutdown(ctx)
 }
 
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hyper-micro/hyper/session"
+)
+
+// ErrNoSessionStore is returned when saving a session on a server without
+// Option.Session.
+var ErrNoSessionStore = errors.New("web: no session store configured")
+
+type SessionOption struct {
+	Store session.Store
+	// CookieName defaults to "session".
+	CookieName string
+	// MaxAge is the lifetime of sessions since they were last saved,
+	// defaulting to 24 hours.
+	MaxAge time.Duration
+	// Path defaults to "/".
+	Path   string
+	Domain string
+	Secure bool
+	// SameSite defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+}
+
+func (o *SessionOption) withDefaults() *SessionOption {
+	opt := *o
+	if opt.CookieName == "" {
+		opt.CookieName = "session"
+	}
+	if opt.MaxAge <= 0 {
+		opt.MaxAge = 24 * time.Hour
+	}
+	if opt.Path == "" {
+		opt.Path = "/"
+	}
+	if opt.SameSite == 0 {
+		opt.SameSite = http.SameSiteLaxMode
+	}
+	return &opt
+}
+
+// Session holds the values of a client's session. Changes are only kept
+// once Save is called, which must happen before the response is written.
+type Session struct {
+	c      *ctx
+	opt    *SessionOption
+	cookie string
+	values map[string]any
+	err    error
+	renew  bool
+}
+
+func (c *ctx) Session() *Session {
+	if c.session != nil {
+		return c.session
+	}
+	s := &Session{c: c, opt: c.srv.Session}
+	if s.opt == nil {
+		s.err = ErrNoSessionStore
+	} else {
+		s.cookie, _ = c.Cookie(s.opt.CookieName)
+		s.values, s.err = s.opt.Store.Load(c, s.cookie)
+		if s.values == nil {
+			s.cookie = ""
+		}
+	}
+	if s.values == nil {
+		s.values = make(map[string]any)
+	}
+	c.session = s
+	return s
+}
+
+// IsNew reports whether the session has not been saved before.
+func (s *Session) IsNew() bool {
+	return s.cookie == ""
+}
+
+func (s *Session) Get(key string) any {
+	return s.values[key]
+}
+
+func (s *Session) Set(key string, value any) {
+	s.values[key] = value
+}
+
+func (s *Session) Delete(key string) {
+	delete(s.values, key)
+}
+
+func (s *Session) Clear() {
+	s.values = make(map[string]any)
+}
+
+// Values returns the session values, which may be changed in place.
+func (s *Session) Values() map[string]any {
+	return s.values
+}
+
+// RenewID gives the session a new ID when it is next saved, and should be
+// called when a user signs in to prevent session fixation.
+func (s *Session) RenewID() {
+	s.renew = true
+}
+
+// Save stores the session and sends its cookie. It returns the error of
+// loading the session, if any, rather than overwrite it.
+func (s *Session) Save() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.renew && s.cookie != "" {
+		if err := s.opt.Store.Delete(s.c, s.cookie); err != nil {
+			return err
+		}
+		s.cookie = ""
+	}
+	s.renew = false
+	cookie, err := s.opt.Store.Save(s.c, s.cookie, s.values, s.opt.MaxAge)
+	if err != nil {
+		return err
+	}
+	s.cookie = cookie
+	s.setCookie(cookie, int(s.opt.MaxAge.Seconds()))
+	return nil
+}
+
+// Destroy deletes the session and expires its cookie.
+func (s *Session) Destroy() error {
+	if s.err != nil && !errors.Is(s.err, ErrNoSessionStore) {
+		return s.err
+	}
+	if s.opt == nil {
+		return ErrNoSessionStore
+	}
+	if s.cookie != "" {
+		if err := s.opt.Store.Delete(s.c, s.cookie); err != nil {
+			return err
+		}
+	}
+	s.cookie = ""
+	s.values = make(map[string]any)
+	s.setCookie("", -1)
+	return nil
+}
+
+func (s *Session) setCookie(value string, maxAge int) {
+	s.c.SetCookie(s.opt.CookieName, value, maxAge, s.opt.Path, s.opt.Domain, s.opt.Secure, true, s.opt.SameSite)
+}
@@ -0,0 +1,64 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyper-micro/hyper/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCtx_Session(t *testing.T) {
+	srv := New(Option{Session: &SessionOption{Store: session.NewMemoryStore()}})
+	srv.Post("/login", WithError(func(ctx Ctx) error {
+		s := ctx.Session()
+		s.Set("user", ctx.QueryString("user"))
+		s.RenewID()
+		return s.Save()
+	}))
+	srv.Get("/me", func(ctx Ctx) {
+		user, _ := ctx.Session().Get("user").(string)
+		_ = ctx.String(user)
+	})
+	srv.Post("/logout", WithError(func(ctx Ctx) error {
+		return ctx.Session().Destroy()
+	}))
+	do := func(method, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/login?user=alice", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	first := cookies[0]
+	assert.Equal(t, "session", first.Name)
+	assert.True(t, first.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, first.SameSite)
+
+	assert.Equal(t, "alice", do(http.MethodGet, "/me", first).Body.String())
+
+	// Signing in again issues a new session ID.
+	second := do(http.MethodPost, "/login?user=bob", first).Result().Cookies()[0]
+	assert.NotEqual(t, first.Value, second.Value)
+	assert.Equal(t, "", do(http.MethodGet, "/me", first).Body.String())
+	assert.Equal(t, "bob", do(http.MethodGet, "/me", second).Body.String())
+
+	rec = do(http.MethodPost, "/logout", second)
+	assert.Equal(t, -1, rec.Result().Cookies()[0].MaxAge)
+	assert.Equal(t, "", do(http.MethodGet, "/me", second).Body.String())
+
+	srv = New(Option{})
+	srv.Get("/", WithError(func(ctx Ctx) error {
+		return ctx.Session().Save()
+	}))
+	assert.Equal(t, http.StatusInternalServerError, do(http.MethodGet, "/", nil).Code)
+}
@@ -0,0 +1,195 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyper-micro/hyper/internal/json"
+)
+
+// ErrStreamClosed is returned when writing to a closed EventStream.
+var ErrStreamClosed = errors.New("web: event stream closed")
+
+type SSEOption struct {
+	// Heartbeat is the interval of comments sent to keep proxies from
+	// closing an idle stream. It defaults to 15 seconds; a negative value
+	// disables heartbeats.
+	Heartbeat time.Duration
+	// Retry, when set, tells the client how long to wait before
+	// reconnecting.
+	Retry time.Duration
+}
+
+// Event is a server-sent event.
+type Event struct {
+	// ID is sent back by a reconnecting client in Last-Event-ID.
+	ID string
+	// Event names the event type, defaulting to "message" on the client.
+	Event string
+	// Data is sent as is when a string or []byte, and as JSON otherwise.
+	Data any
+	// Retry, when set, changes the reconnection delay of the client.
+	Retry time.Duration
+}
+
+// EventStream writes server-sent events. It is safe for concurrent use.
+type EventStream struct {
+	c    *ctx
+	rc   *http.ResponseController
+	mu   sync.Mutex
+	err  error
+	stop chan struct{}
+	once sync.Once
+}
+
+func (c *ctx) SSE(opt ...SSEOption) (*EventStream, error) {
+	var o SSEOption
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	if o.Heartbeat == 0 {
+		o.Heartbeat = 15 * time.Second
+	}
+
+	h := c.w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	// Keep nginx from buffering the stream.
+	h.Set("X-Accel-Buffering", "no")
+	h.Del("Content-Length")
+
+	s := &EventStream{c: c, rc: http.NewResponseController(c.w), stop: make(chan struct{})}
+	// Streams outlive the server's WriteTimeout.
+	_ = s.rc.SetWriteDeadline(time.Time{})
+	c.Status(http.StatusOK)
+	if o.Retry > 0 {
+		s.err = s.write("retry: " + strconv.FormatInt(o.Retry.Milliseconds(), 10) + "\n\n")
+	} else {
+		s.err = s.rc.Flush()
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	if o.Heartbeat > 0 {
+		go s.heartbeat(o.Heartbeat)
+	}
+	return s, nil
+}
+
+func (s *EventStream) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.Comment("heartbeat") != nil {
+				return
+			}
+		case <-s.stop:
+			return
+		case <-s.c.Done():
+			return
+		}
+	}
+}
+
+// LastEventID is the ID of the last event a reconnecting client received,
+// from which the stream should resume.
+func (s *EventStream) LastEventID() string {
+	return s.c.GetHeader("Last-Event-ID")
+}
+
+// Done is closed when the client goes away.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.c.Done()
+}
+
+// Send writes and flushes e.
+func (s *EventStream) Send(e Event) error {
+	if strings.ContainsAny(e.ID, "\r\n\x00") || strings.ContainsAny(e.Event, "\r\n") {
+		return errors.New("web: event ID and name must be single lines")
+	}
+	var data []byte
+	switch d := e.Data.(type) {
+	case string:
+		data = []byte(d)
+	case []byte:
+		data = d
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		data = b
+	}
+
+	var buf bytes.Buffer
+	if e.ID != "" {
+		buf.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		buf.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(normalizeNewlines(string(data)), "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.String())
+}
+
+// Comment writes a comment line, which clients ignore.
+func (s *EventStream) Comment(text string) error {
+	var buf strings.Builder
+	for _, line := range strings.Split(normalizeNewlines(text), "\n") {
+		buf.WriteString(": " + line + "\n")
+	}
+	buf.WriteByte('\n')
+	return s.write(buf.String())
+}
+
+// normalizeNewlines turns the "\r\n" and lone "\r" line endings of the
+// event stream format into "\n", so no line of the input can start a field.
+func normalizeNewlines(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+func (s *EventStream) write(msg string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.c.Err(); err != nil {
+		s.err = err
+		return err
+	}
+	if _, err := s.c.w.Write([]byte(msg)); err != nil {
+		s.err = err
+		return err
+	}
+	if err := s.rc.Flush(); err != nil {
+		s.err = err
+	}
+	return s.err
+}
+
+// Close stops the heartbeat and any further writes. It must be called
+// before the handler returns.
+func (s *EventStream) Close() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = ErrStreamClosed
+	}
+}
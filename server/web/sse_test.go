@@ -0,0 +1,77 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCtx_SSE(t *testing.T) {
+	closed := make(chan error, 1)
+	srv := New(Option{})
+	srv.Get("/events", WithError(func(ctx Ctx) error {
+		stream, err := ctx.SSE(SSEOption{Heartbeat: 20 * time.Millisecond, Retry: 3 * time.Second})
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+
+		next, _ := strconv.Atoi(stream.LastEventID())
+		_ = stream.Send(Event{ID: strconv.Itoa(next + 1), Data: "line1\nline2"})
+		_ = stream.Send(Event{ID: strconv.Itoa(next + 2), Event: "update", Data: map[string]int{"n": next + 2}})
+		assert.Error(t, stream.Send(Event{ID: "a\nb"}))
+		_ = stream.Send(Event{Data: "x\rid: 9\revent: admin"})
+		_ = stream.Comment("a\rretry: 1")
+
+		<-stream.Done()
+		closed <- stream.Send(Event{Data: "late"})
+		return nil
+	}))
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "41")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	r := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return strings.Join(lines, "")
+			}
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, "retry: 3000\n", readEvent())
+	assert.Equal(t, "id: 42\ndata: line1\ndata: line2\n", readEvent())
+	assert.Equal(t, "id: 43\nevent: update\ndata: {\"n\":43}\n", readEvent())
+	assert.Equal(t, "data: x\ndata: id: 9\ndata: event: admin\n", readEvent())
+	assert.Equal(t, ": a\n: retry: 1\n", readEvent())
+	assert.Equal(t, ": heartbeat\n", readEvent())
+
+	cancel()
+	select {
+	case err := <-closed:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("handler did not see the client disconnect")
+	}
+}
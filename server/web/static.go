@@ -0,0 +1,225 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+type StaticOption struct {
+	// Index is served for directories and defaults to "index.html".
+	Index string
+	// SPA serves the root Index for paths matching no file, leaving them to
+	// a client-side router.
+	SPA bool
+	// Precompressed serves the ".br" or ".gz" sibling of a file, when there
+	// is one and the client accepts its encoding.
+	Precompressed bool
+	// CacheControl is sent with every file, such as "public, max-age=3600".
+	CacheControl string
+	// CacheControlFunc, when set, chooses the Cache-Control of each file by
+	// its name, such as "no-cache" for index.html and a long max-age for
+	// fingerprinted assets.
+	CacheControlFunc func(name string) string
+}
+
+// Static serves the files of dir under prefix. See StaticFS.
+func (g *Group) Static(prefix, dir string, opt ...StaticOption) *Route {
+	return g.StaticFS(prefix, os.DirFS(dir), opt...)
+}
+
+// StaticFS serves the files of fsys, such as an embed.FS, for GET and HEAD
+// requests under prefix. Responses carry an ETag and, when known, a
+// Last-Modified header, and support conditional and Range requests. Names
+// starting with a dot are never served.
+func (g *Group) StaticFS(prefix string, fsys fs.FS, opt ...StaticOption) *Route {
+	var o StaticOption
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	if o.Index == "" {
+		o.Index = "index.html"
+	}
+	s := &staticFS{fsys: fsys, opt: o}
+	route := g.r.PathPrefix(prefix).Methods(http.MethodGet, http.MethodHead)
+	return &Route{r: route.HandlerFunc(g.wrapHandler(s.serve, nil))}
+}
+
+type staticFS struct {
+	fsys fs.FS
+	opt  StaticOption
+	// etags caches the content hashes of files without a modification
+	// time, such as those of an embed.FS.
+	etags sync.Map
+}
+
+func (s *staticFS) serve(ctx Ctx) {
+	req := ctx.Request()
+	prefix := ""
+	if route := mux.CurrentRoute(req); route != nil {
+		prefix, _ = route.GetPathTemplate()
+	}
+	name := path.Clean("/" + strings.TrimPrefix(req.URL.Path, prefix))[1:]
+	if name == "" {
+		name = "."
+	}
+	if hidden(name) {
+		ctx.Error(NewHTTPError(http.StatusNotFound))
+		return
+	}
+
+	info, err := fs.Stat(s.fsys, name)
+	if err == nil && info.IsDir() {
+		if !strings.HasSuffix(req.URL.Path, "/") {
+			u := *req.URL
+			u.Path += "/"
+			http.Redirect(ctx.Writer(), req, u.String(), http.StatusMovedPermanently)
+			ctx.Abort()
+			return
+		}
+		name = path.Join(name, s.opt.Index)
+		info, err = fs.Stat(s.fsys, name)
+	}
+	if err != nil && s.opt.SPA && errors.Is(err, fs.ErrNotExist) {
+		name = s.opt.Index
+		info, err = fs.Stat(s.fsys, name)
+	}
+	if err != nil || info.IsDir() {
+		if err == nil || errors.Is(err, fs.ErrNotExist) {
+			err = NewHTTPError(http.StatusNotFound)
+		}
+		ctx.Error(err)
+		return
+	}
+
+	if err := s.serveFile(ctx, name, info); err != nil {
+		ctx.Error(err)
+	}
+}
+
+func hidden(name string) bool {
+	for _, seg := range strings.Split(name, "/") {
+		if strings.HasPrefix(seg, ".") && seg != "." {
+			return true
+		}
+	}
+	return false
+}
+
+var precompressed = []struct {
+	encoding, ext string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+func (s *staticFS) serveFile(ctx Ctx, name string, info fs.FileInfo) error {
+	h := ctx.Writer().Header()
+	served, servedInfo := name, info
+	if s.opt.Precompressed {
+		h.Add("Vary", "Accept-Encoding")
+		accept := ctx.GetHeader("Accept-Encoding")
+		for _, p := range precompressed {
+			if !acceptsEncoding(accept, p.encoding) {
+				continue
+			}
+			if pi, err := fs.Stat(s.fsys, name+p.ext); err == nil && !pi.IsDir() {
+				served, servedInfo = name+p.ext, pi
+				h.Set("Content-Encoding", p.encoding)
+				break
+			}
+		}
+	}
+
+	f, err := s.fsys.Open(served)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		content = bytes.NewReader(b)
+	}
+
+	if served != name && h.Get("Content-Type") == "" {
+		// ServeContent would otherwise sniff the compressed bytes.
+		ctype := mime.TypeByExtension(path.Ext(name))
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+		h.Set("Content-Type", ctype)
+	}
+	etag, err := s.etag(served, servedInfo, content)
+	if err != nil {
+		return err
+	}
+	h.Set("ETag", etag)
+	if cc := s.cacheControl(name); cc != "" {
+		h.Set("Cache-Control", cc)
+	}
+	http.ServeContent(ctx.Writer(), ctx.Request(), name, servedInfo.ModTime(), content)
+	return nil
+}
+
+func (s *staticFS) cacheControl(name string) string {
+	if s.opt.CacheControlFunc != nil {
+		return s.opt.CacheControlFunc(name)
+	}
+	return s.opt.CacheControl
+}
+
+// etag derives an ETag from the modification time and size of a file, or
+// from its content when it has no modification time.
+func (s *staticFS) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if !info.ModTime().IsZero() {
+		return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()), nil
+	}
+	if etag, ok := s.etags.Load(name); ok {
+		return etag.(string), nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	s.etags.Store(name, etag)
+	return etag, nil
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows coding.
+func acceptsEncoding(accept, coding string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		c, params, _ := strings.Cut(part, ";")
+		c = strings.TrimSpace(c)
+		if !strings.EqualFold(c, coding) && c != "*" {
+			continue
+		}
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if q, err := strconv.ParseFloat(v, 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
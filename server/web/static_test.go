@@ -0,0 +1,100 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup_StaticFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":       {Data: []byte("<html>app</html>")},
+		"app.js":           {Data: []byte("console.log('app')")},
+		"app.js.gz":        {Data: []byte("gzipped")},
+		"docs/index.html":  {Data: []byte("docs")},
+		".env":             {Data: []byte("SECRET=1")},
+		"assets/logo.svg":  {Data: []byte("<svg/>")},
+		"assets/.git/HEAD": {Data: []byte("ref")},
+	}
+	srv := New(Option{})
+	srv.Group("/ui").StaticFS("/", fsys, StaticOption{
+		SPA:           true,
+		Precompressed: true,
+		CacheControlFunc: func(name string) string {
+			if name == "index.html" {
+				return "no-cache"
+			}
+			return "public, max-age=31536000, immutable"
+		},
+	})
+	srv.StaticFS("/plain/", fsys)
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/ui/app.js")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "console.log('app')", rec.Body.String())
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusNotModified, get("/ui/app.js", "If-None-Match", etag).Code)
+
+	rec = get("/ui/app.js", "Range", "bytes=0-6")
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, "console", rec.Body.String())
+
+	rec = get("/ui/app.js", "Accept-Encoding", "gzip, br")
+	assert.Equal(t, "gzipped", rec.Body.String())
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/javascript"))
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	assert.Equal(t, "console.log('app')", get("/ui/app.js", "Accept-Encoding", "gzip;q=0").Body.String())
+
+	rec = get("/ui/docs")
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/ui/docs/", rec.Header().Get("Location"))
+	assert.Equal(t, "docs", get("/ui/docs/").Body.String())
+
+	rec = get("/ui/settings/profile")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "<html>app</html>", rec.Body.String())
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+
+	assert.Equal(t, http.StatusNotFound, get("/ui/.env").Code)
+	assert.Equal(t, http.StatusNotFound, get("/ui/assets/.git/HEAD").Code)
+	assert.Equal(t, http.StatusNotFound, get("/plain/missing.js").Code)
+	assert.Equal(t, "<svg/>", get("/plain/assets/logo.svg").Body.String())
+}
+
+func TestGroup_Static(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0o600))
+	srv := New(Option{})
+	srv.Static("/files/", dir, StaticOption{CacheControl: "public, max-age=60"})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/a.txt", nil))
+	assert.Equal(t, "hello", rec.Body.String())
+	assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+	assert.Equal(t, "public, max-age=60", rec.Header().Get("Cache-Control"))
+
+	req := httptest.NewRequest(http.MethodGet, "/files/a.txt", nil)
+	req.Header.Set("If-Modified-Since", rec.Header().Get("Last-Modified"))
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sync"
+)
+
+// HTMLRenderer renders the named HTML template with data, for Ctx.HTML.
+type HTMLRenderer interface {
+	Render(w io.Writer, name string, data any) error
+}
+
+type TemplateOption struct {
+	// Dir holds the templates, unless FS is set, such as to an embed.FS.
+	Dir string
+	FS  fs.FS
+	// Extensions selects the template files, defaulting to ".html" and
+	// ".tmpl". Templates are named by their path, such as "users/show.html".
+	Extensions []string
+	Funcs      template.FuncMap
+	// LeftDelim and RightDelim default to "{{" and "}}".
+	LeftDelim, RightDelim string
+	// Reload parses the templates again on every render, so edits show up
+	// without a restart. It is meant for development.
+	Reload bool
+}
+
+// Templates is an HTMLRenderer of html/template files. Every file can use
+// the templates defined in the others, such as layouts and partials.
+type Templates struct {
+	opt TemplateOption
+	mu  sync.RWMutex
+	t   *template.Template
+}
+
+func NewTemplates(opt TemplateOption) (*Templates, error) {
+	if opt.FS == nil {
+		opt.FS = os.DirFS(opt.Dir)
+	}
+	if len(opt.Extensions) == 0 {
+		opt.Extensions = []string{".html", ".tmpl"}
+	}
+	t := &Templates{opt: opt}
+	if err := t.Load(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Load parses the templates again.
+func (t *Templates) Load() error {
+	root := template.New("").Delims(t.opt.LeftDelim, t.opt.RightDelim).Funcs(t.opt.Funcs)
+	err := fs.WalkDir(t.opt.FS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !t.matches(name) {
+			return err
+		}
+		b, err := fs.ReadFile(t.opt.FS, name)
+		if err != nil {
+			return err
+		}
+		_, err = root.New(name).Parse(string(b))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("web: load templates: %w", err)
+	}
+
+	t.mu.Lock()
+	t.t = root
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *Templates) matches(name string) bool {
+	ext := path.Ext(name)
+	for _, e := range t.opt.Extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Templates) Render(w io.Writer, name string, data any) error {
+	if t.opt.Reload {
+		if err := t.Load(); err != nil {
+			return err
+		}
+	}
+	t.mu.RLock()
+	root := t.t
+	t.mu.RUnlock()
+	return root.ExecuteTemplate(w, name, data)
+}
@@ -0,0 +1,202 @@
+package web
+
+import (
+	stdErrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/ja"
+	"github.com/go-playground/locales/pt_BR"
+	"github.com/go-playground/locales/ru"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTrans "github.com/go-playground/validator/v10/translations/en"
+	esTrans "github.com/go-playground/validator/v10/translations/es"
+	frTrans "github.com/go-playground/validator/v10/translations/fr"
+	jaTrans "github.com/go-playground/validator/v10/translations/ja"
+	ptBRTrans "github.com/go-playground/validator/v10/translations/pt_BR"
+	ruTrans "github.com/go-playground/validator/v10/translations/ru"
+	zhTrans "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// DefaultLocale is used for validation messages when neither the request
+// nor the server option selects a supported locale.
+const DefaultLocale = "en"
+
+var (
+	validate = validator.New(validator.WithRequiredStructEnabled())
+	uni      *ut.UniversalTranslator
+)
+
+func init() {
+	validate.RegisterTagNameFunc(fieldName)
+
+	bundled := []struct {
+		locale   locales.Translator
+		register func(v *validator.Validate, trans ut.Translator) error
+	}{
+		{en.New(), enTrans.RegisterDefaultTranslations},
+		{zh.New(), zhTrans.RegisterDefaultTranslations},
+		{ja.New(), jaTrans.RegisterDefaultTranslations},
+		{es.New(), esTrans.RegisterDefaultTranslations},
+		{fr.New(), frTrans.RegisterDefaultTranslations},
+		{pt_BR.New(), ptBRTrans.RegisterDefaultTranslations},
+		{ru.New(), ruTrans.RegisterDefaultTranslations},
+	}
+	uni = ut.New(bundled[0].locale)
+	for _, b := range bundled {
+		_ = uni.AddTranslator(b.locale, true)
+		trans, _ := uni.GetTranslator(b.locale.Locale())
+		if err := b.register(validate, trans); err != nil {
+			panic(fmt.Sprintf("web: register %s validation translations: %v", b.locale.Locale(), err))
+		}
+	}
+}
+
+// RegisterValidation adds a validation tag to the validator used by
+// binding, with a message per locale such as
+// {"en": "{0} must be a valid slug"}, where {0} is the field name and {1}
+// the tag parameter. Locales not bundled are ignored. It must be called
+// before the server starts serving.
+func RegisterValidation(tag string, fn validator.Func, messages map[string]string) error {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	for locale, msg := range messages {
+		trans, ok := uni.GetTranslator(normalizeLocale(locale))
+		if !ok {
+			continue
+		}
+		err := validate.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+			return trans.Add(tag, msg, true)
+		}, func(trans ut.Translator, fe validator.FieldError) string {
+			s, err := trans.T(tag, fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return s
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldName names struct fields in validation errors after the first of
+// their binding tags, falling back to the Go name.
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"json", "form", "query", "uri", "header", "xml", "msgpack"} {
+		name, _, _ := strings.Cut(sf.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// FieldErrors converts the validator.ValidationErrors in err into field
+// errors with messages in locale, or in DefaultLocale if it is not
+// bundled. A BindingError returns its own fields. It returns nil for any
+// other error.
+func FieldErrors(err error, locale string) []FieldError {
+	var bindErr *BindingError
+	if stdErrors.As(err, &bindErr) && bindErr.Fields != nil {
+		return bindErr.Fields
+	}
+	var ves validator.ValidationErrors
+	if !stdErrors.As(err, &ves) {
+		return nil
+	}
+
+	trans, ok := uni.GetTranslator(normalizeLocale(locale))
+	if !ok {
+		trans, _ = uni.GetTranslator(DefaultLocale)
+	}
+	fields := make([]FieldError, len(ves))
+	for i, fe := range ves {
+		field := fe.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+		msg := fe.Translate(trans)
+		if msg == fe.Error() {
+			msg = fmt.Sprintf("%s failed on the '%s' tag", field, fe.Tag())
+		}
+		fields[i] = FieldError{
+			Field:   field,
+			Tag:     fe.Tag(),
+			Param:   fe.Param(),
+			Message: msg,
+		}
+	}
+	return fields
+}
+
+// validateStruct validates d when it is a struct or a pointer to one,
+// reporting failures as a BindingError with messages in locale.
+func validateStruct(d any, locale string) error {
+	t := reflect.TypeOf(d)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	err := validate.Struct(d)
+	if fields := FieldErrors(err, locale); fields != nil {
+		return &BindingError{Err: err, Fields: fields}
+	}
+	return err
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "-", "_"))
+}
+
+// matchLocale returns the bundled locale best matching an Accept-Language
+// header, trying each language range by weight and then its base
+// language.
+func matchLocale(acceptLanguage string) (string, bool) {
+	type langRange struct {
+		tag string
+		q   float64
+	}
+	var ranges []langRange
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if tag = normalizeLocale(tag); tag != "" && tag != "*" && q > 0 {
+			ranges = append(ranges, langRange{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	for _, r := range ranges {
+		if trans, ok := uni.GetTranslator(r.tag); ok {
+			return trans.Locale(), true
+		}
+		if base, _, ok := strings.Cut(r.tag, "_"); ok {
+			if trans, ok := uni.GetTranslator(base); ok {
+				return trans.Locale(), true
+			}
+		}
+	}
+	return "", false
+}
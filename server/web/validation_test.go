@@ -0,0 +1,69 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchLocale(t *testing.T) {
+	for header, want := range map[string]string{
+		"zh-CN,zh;q=0.9,en;q=0.8": "zh",
+		"de;q=0.9, ja;q=0.5":      "ja",
+		"en;q=0.1, fr":            "fr",
+		"pt-BR":                   "pt_BR",
+		"pt-PT":                   "",
+		"*":                       "",
+	} {
+		locale, _ := matchLocale(header)
+		assert.Equal(t, want, locale, header)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	slug := regexp.MustCompile(`^[a-z0-9-]+$`)
+	require.NoError(t, RegisterValidation("slug", func(fl validator.FieldLevel) bool {
+		return slug.MatchString(fl.Field().String())
+	}, map[string]string{
+		"en": "{0} must be a valid slug",
+		"zh": "{0}必须是有效的别名",
+	}))
+	require.NoError(t, RegisterValidation("even", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int()%2 == 0
+	}, nil))
+
+	type post struct {
+		Slug  string `json:"slug" validate:"slug"`
+		Count int    `json:"count" validate:"even"`
+		Title string `json:"title" validate:"required"`
+	}
+	srv := New(Option{Locale: "zh"})
+	srv.Post("/posts", func(ctx Ctx) {
+		var p post
+		_ = ctx.Bind(&p)
+	})
+
+	for lang, want := range map[string][]string{
+		"en-US": {"slug must be a valid slug", "count failed on the 'even' tag", "title is a required field"},
+		"":      {"slug必须是有效的别名", "count failed on the 'even' tag", "title为必填字段"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(`{"slug":"Not A Slug","count":3}`))
+		req.Header.Set("Accept-Language", lang)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		for _, msg := range want {
+			assert.Contains(t, rec.Body.String(), msg, lang)
+		}
+	}
+
+	err := validate.Struct(post{Slug: "ok", Count: 2})
+	assert.Equal(t, []FieldError{{Field: "title", Tag: "required", Message: "title est un champ obligatoire"}}, FieldErrors(err, "fr"))
+	assert.Nil(t, FieldErrors(assert.AnError, "en"))
+}
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"net"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -14,8 +15,9 @@ const (
 )
 
 type Conn struct {
-	srv  *Server
-	conn *websocket.Conn
+	srv       *Server
+	conn      *websocket.Conn
+	closeOnce sync.Once
 }
 
 func newConn(srv *Server, c *websocket.Conn) *Conn {
@@ -40,6 +42,11 @@ func (c *Conn) Write(p []byte) (n int, err error) {
 }
 
 func (c *Conn) Close() error {
+	c.closeOnce.Do(func() {
+		if c.srv.metrics != nil {
+			c.srv.metrics.active.Dec()
+		}
+	})
 	return c.conn.Close()
 }
 
@@ -6,10 +6,13 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/metrics"
 )
 
 type Config struct {
@@ -40,6 +43,9 @@ type Option struct {
 	ConnContext    func(ctx context.Context, c net.Conn) context.Context
 	CheckOrigin    func(r *http.Request) bool
 	MessageType    int
+	PanicHandler   PanicHandler
+	// Metrics, when set, tracks open and accepted connections.
+	Metrics *metrics.Registry
 }
 
 type Server struct {
@@ -48,9 +54,16 @@ type Server struct {
 	up      websocket.Upgrader
 	srv     *http.Server
 	handler Handler
+	ln      net.Listener
+	lnMu    sync.Mutex
+	metrics *serverMetrics
 }
 
 func New(opt Option) *Server {
+	if opt.Logger == nil {
+		opt.Logger = logger.Default()
+	}
+
 	ws := &Server{
 		Option: opt,
 		up: websocket.Upgrader{
@@ -61,6 +74,13 @@ func New(opt Option) *Server {
 		},
 	}
 
+	if opt.Metrics != nil {
+		ws.metrics = &serverMetrics{
+			active:   opt.Metrics.NewGauge("websocket_connections", "Open WebSocket connections."),
+			accepted: opt.Metrics.NewCounter("websocket_connections_total", "WebSocket upgrades, by result.", "result"),
+		}
+	}
+
 	ws.srv = &http.Server{
 		Addr:              opt.Addr,
 		Handler:           ws,
@@ -78,16 +98,61 @@ func New(opt Option) *Server {
 	return ws
 }
 
+func (s *Server) Listen(ctx context.Context) error {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln != nil {
+		return nil
+	}
+	addr := s.srv.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := new(net.ListenConfig).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	return nil
+}
+
+// SetListener makes the server serve on ln, such as a listener inherited
+// from a parent process, instead of binding its own. It has no effect once
+// the server is listening.
+func (s *Server) SetListener(ln net.Listener) {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	if s.ln == nil {
+		s.ln = ln
+	}
+}
+
+func (s *Server) Listener() net.Listener {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.ln
+}
+
+func (s *Server) Listening() bool {
+	s.lnMu.Lock()
+	defer s.lnMu.Unlock()
+	return s.ln != nil
+}
+
 func (s *Server) Run() error {
+	if err := s.Listen(context.Background()); err != nil {
+		return err
+	}
+
 	var (
 		srvErr error
 	)
 	for {
 		if s.Option.CertFile != "" && s.Option.KeyFile != "" {
-			srvErr = s.srv.ListenAndServeTLS(s.Option.CertFile, s.Option.KeyFile)
+			srvErr = s.srv.ServeTLS(s.ln, s.Option.CertFile, s.Option.KeyFile)
 			break
 		}
-		srvErr = s.srv.ListenAndServe()
+		srvErr = s.srv.Serve(s.ln)
 		break
 	}
 	if srvErr == http.ErrServerClosed {
@@ -104,11 +169,23 @@ func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
+	return s.ShutdownContext(ctx)
+}
+
+func (s *Server) ShutdownContext(ctx context.Context) error {
 	return s.srv.Shutdown(ctx)
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	wsConn, err := s.up.Upgrade(w, r, make(http.Header))
+	if s.metrics != nil {
+		if err != nil {
+			s.metrics.accepted.Inc("error")
+		} else {
+			s.metrics.accepted.Inc("ok")
+			s.metrics.active.Inc()
+		}
+	}
 	if err != nil {
 		s.Option.Logger.Errorf("websocket: Connect err: %s, remoteAddr: %s", err.Error(), r.RemoteAddr)
 		return
@@ -119,10 +196,34 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.Option.Logger.Infof("websocket: Connection established, localAddr: %s, remoteAddr: %s", rwc.LocalAddr().String(), rwc.RemoteAddr().String())
 
 	if s.handler != nil {
+		defer s.recoverPanic(rwc)
 		s.handler.OnConnection(rwc)
 	}
 }
 
+// PanicHandler reports a panic recovered from Handler.OnConnection, such as
+// to an error tracker. The connection is closed after it returns.
+type PanicHandler func(conn net.Conn, p any, stack []byte)
+
+// recoverPanic must be deferred directly.
+func (s *Server) recoverPanic(conn *Conn) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	stack := debug.Stack()
+	s.Option.Logger.Errorf("websocket: panic serving remoteAddr: %s: %v\n%s", conn.RemoteAddr().String(), p, stack)
+	if s.Option.PanicHandler != nil {
+		s.Option.PanicHandler(conn, p, stack)
+	}
+	_ = conn.Close()
+}
+
+type serverMetrics struct {
+	active   *metrics.Gauge
+	accepted *metrics.Counter
+}
+
 func (s *Server) Handler(handler Handler) {
 	s.handler = handler
 }
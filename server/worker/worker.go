@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyper-micro/hyper/logger"
+)
+
+// Handler processes work until ctx is cancelled. Returning ctx.Err() after
+// cancellation is treated as a clean stop.
+type Handler func(ctx context.Context) error
+
+type Option struct {
+	Name string
+	// Concurrency is the number of handler goroutines, at least one.
+	Concurrency int
+	// RestartDelay, when positive, restarts a handler that returned an error
+	// or panicked after the delay instead of stopping the worker.
+	RestartDelay    time.Duration
+	ShutdownTimeout time.Duration
+	Logger          logger.Logger
+}
+
+type Worker struct {
+	Option
+
+	handler Handler
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started atomic.Bool
+	stopped chan struct{}
+}
+
+func New(opt Option) *Worker {
+	if opt.Name == "" {
+		opt.Name = "worker"
+	}
+	if opt.Concurrency < 1 {
+		opt.Concurrency = 1
+	}
+	if opt.Logger == nil {
+		opt.Logger = logger.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Worker{
+		Option:  opt,
+		ctx:     ctx,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+	}
+}
+
+func (w *Worker) Handler(handler Handler) {
+	w.handler = handler
+}
+
+// Run starts the handlers and blocks until they all return. The first
+// error stops the remaining handlers unless RestartDelay is set.
+func (w *Worker) Run() error {
+	if w.handler == nil {
+		return fmt.Errorf("worker: %s has no handler", w.Name())
+	}
+	if !w.started.CompareAndSwap(false, true) {
+		return fmt.Errorf("worker: %s already running", w.Name())
+	}
+	defer close(w.stopped)
+
+	var (
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		runErr  error
+	)
+	for i := 0; i < w.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.loop(); err != nil {
+				errOnce.Do(func() {
+					runErr = err
+					w.cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	return runErr
+}
+
+func (w *Worker) loop() error {
+	for {
+		err := w.call()
+		if err == nil || w.ctx.Err() != nil {
+			return nil
+		}
+		if w.RestartDelay <= 0 {
+			return err
+		}
+
+		w.Logger.Errorf("worker: %s failed, restarting in %s: %v", w.Name(), w.RestartDelay, err)
+		timer := time.NewTimer(w.RestartDelay)
+		select {
+		case <-w.ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+func (w *Worker) call() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	err = w.handler(w.ctx)
+	if errors.Is(err, context.Canceled) && w.ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func (w *Worker) Shutdown() error {
+	shutdownTimeout := w.ShutdownTimeout
+	if shutdownTimeout == 0 {
+		shutdownTimeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return w.ShutdownContext(ctx)
+}
+
+// ShutdownContext cancels the handler context and waits for the handlers to
+// return until ctx expires.
+func (w *Worker) ShutdownContext(ctx context.Context) error {
+	w.cancel()
+	if !w.started.Load() {
+		return nil
+	}
+
+	select {
+	case <-w.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) Addr() string {
+	return ""
+}
+
+func (w *Worker) Name() string {
+	return w.Option.Name
+}
@@ -0,0 +1,106 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyper-micro/hyper/logger"
+	"github.com/hyper-micro/hyper/logger/loggertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorker_ConcurrencyAndShutdown(t *testing.T) {
+	w := New(Option{Concurrency: 3, Logger: loggertest.New()})
+	var active atomic.Int32
+	w.Handler(func(ctx context.Context) error {
+		active.Add(1)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run()
+	}()
+	assert.Eventually(t, func() bool {
+		return active.Load() == 3
+	}, time.Second, 5*time.Millisecond)
+	assert.Error(t, w.Run())
+
+	require.NoError(t, w.Shutdown())
+	assert.NoError(t, <-done)
+	assert.Equal(t, "worker", w.Name())
+}
+
+func TestWorker_FirstErrorCancels(t *testing.T) {
+	w := New(Option{Concurrency: 2, Logger: loggertest.New()})
+	var calls atomic.Int32
+	w.Handler(func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			return errors.New("boom")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	assert.EqualError(t, w.Run(), "boom")
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestWorker_RestartAfterPanic(t *testing.T) {
+	log := loggertest.New()
+	w := New(Option{Name: "consumer", RestartDelay: 5 * time.Millisecond, Logger: log})
+	var calls atomic.Int32
+	w.Handler(func(ctx context.Context) error {
+		if calls.Add(1) <= 2 {
+			panic("boom")
+		}
+		<-ctx.Done()
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run()
+	}()
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 3
+	}, time.Second, 5*time.Millisecond)
+	assert.Len(t, log.Entries().FilterLevel(logger.ErrorLevel), 2)
+	log.AssertLogged(t, logger.ErrorLevel, "panic: boom")
+
+	require.NoError(t, w.Shutdown())
+	assert.NoError(t, <-done)
+}
+
+func TestWorker_ShutdownContext(t *testing.T) {
+	w := New(Option{Logger: loggertest.New()})
+	assert.Error(t, w.Run())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	w.Handler(func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- w.Run()
+	}()
+	<-started
+
+	// The handler ignores cancellation, so shutdown gives up at the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, w.ShutdownContext(ctx), context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.NoError(t, w.ShutdownContext(context.Background()))
+}
@@ -0,0 +1,131 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxCookieSize is the value size most browsers accept for a cookie,
+// less room for its name and attributes.
+const maxCookieSize = 3800
+
+// CookieStore keeps session values in the cookie itself, signed and
+// optionally encrypted, so it needs no storage but cannot revoke sessions.
+type CookieStore struct {
+	hashKey []byte
+	aead    cipher.AEAD
+	now     func() time.Time
+}
+
+// NewCookieStore signs cookies with hashKey, which must be at least 32
+// bytes. A blockKey of 16, 24 or 32 bytes also encrypts them with AES-GCM.
+func NewCookieStore(hashKey, blockKey []byte) (*CookieStore, error) {
+	if len(hashKey) < 32 {
+		return nil, errors.New("session: hash key must be at least 32 bytes")
+	}
+	s := &CookieStore{hashKey: hashKey, now: time.Now}
+	if len(blockKey) > 0 {
+		block, err := aes.NewCipher(blockKey)
+		if err != nil {
+			return nil, fmt.Errorf("session: block key: %w", err)
+		}
+		if s.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Load returns nil for cookies that are expired, tampered with or were
+// made with other keys.
+func (s *CookieStore) Load(_ context.Context, cookie string) (map[string]any, error) {
+	if cookie == "" {
+		return nil, nil
+	}
+	payload, ok := s.open(cookie)
+	if !ok || len(payload) < 8 {
+		return nil, nil
+	}
+	expires := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+	if !s.now().Before(expires) {
+		return nil, nil
+	}
+	values, err := decode(payload[8:])
+	if err != nil {
+		return nil, nil
+	}
+	return values, nil
+}
+
+func (s *CookieStore) Save(_ context.Context, _ string, values map[string]any, maxAge time.Duration) (string, error) {
+	b, err := encode(values)
+	if err != nil {
+		return "", fmt.Errorf("session: encode: %w", err)
+	}
+	payload := make([]byte, 8, 8+len(b))
+	binary.BigEndian.PutUint64(payload, uint64(s.now().Add(maxAge).Unix()))
+	payload = append(payload, b...)
+
+	cookie := s.seal(payload)
+	if len(cookie) > maxCookieSize {
+		return "", fmt.Errorf("session: cookie of %d bytes is too large", len(cookie))
+	}
+	return cookie, nil
+}
+
+// Delete does nothing, as the session lives only in the cookie.
+func (s *CookieStore) Delete(context.Context, string) error {
+	return nil
+}
+
+func (s *CookieStore) mac(b []byte) []byte {
+	h := hmac.New(sha256.New, s.hashKey)
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+func (s *CookieStore) seal(payload []byte) string {
+	enc := base64.RawURLEncoding
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(payload)+s.aead.Overhead())
+		_, _ = rand.Read(nonce)
+		return enc.EncodeToString(s.aead.Seal(nonce, nonce, payload, s.hashKey))
+	}
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(s.mac(payload))
+}
+
+func (s *CookieStore) open(cookie string) ([]byte, bool) {
+	enc := base64.RawURLEncoding
+	if s.aead != nil {
+		b, err := enc.DecodeString(cookie)
+		if err != nil || len(b) < s.aead.NonceSize() {
+			return nil, false
+		}
+		nonce, sealed := b[:s.aead.NonceSize()], b[s.aead.NonceSize():]
+		payload, err := s.aead.Open(nil, nonce, sealed, s.hashKey)
+		return payload, err == nil
+	}
+	encPayload, encMAC, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return nil, false
+	}
+	payload, err := enc.DecodeString(encPayload)
+	if err != nil {
+		return nil, false
+	}
+	mac, err := enc.DecodeString(encMAC)
+	if err != nil || !hmac.Equal(mac, s.mac(payload)) {
+		return nil, false
+	}
+	return payload, true
+}
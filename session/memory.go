@@ -0,0 +1,84 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryStore holds sessions in a map of this process. Sessions are lost on
+// restart and are not seen by other replicas, so it suits development and
+// single-instance deployments.
+type MemoryStore struct {
+	mu        sync.Mutex
+	sessions  map[string]*memorySession
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type memorySession struct {
+	data    []byte
+	expires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]*memorySession),
+		now:      time.Now,
+	}
+}
+
+// Expired sessions are dropped by Save at most once per sweepInterval, so
+// abandoned sessions do not pile up without a background goroutine.
+const sweepInterval = time.Minute
+
+func (s *MemoryStore) Load(_ context.Context, cookie string) (map[string]any, error) {
+	s.mu.Lock()
+	sess, ok := s.sessions[cookie]
+	s.mu.Unlock()
+	if !ok || !s.now().Before(sess.expires) {
+		return nil, nil
+	}
+	return decode(sess.data)
+}
+
+func (s *MemoryStore) Save(_ context.Context, cookie string, values map[string]any, maxAge time.Duration) (string, error) {
+	b, err := encode(values)
+	if err != nil {
+		return "", fmt.Errorf("session: encode: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.dropExpired(now)
+	// Cookies of unknown sessions are not reused, so clients cannot choose
+	// their session ID.
+	if _, ok := s.sessions[cookie]; !ok {
+		cookie = newID()
+	}
+	s.sessions[cookie] = &memorySession{data: b, expires: now.Add(maxAge)}
+	return cookie, nil
+}
+
+// dropExpired removes the expired sessions if the last sweep was long
+// enough ago. s.mu must be held.
+func (s *MemoryStore) dropExpired(now time.Time) {
+	if now.Sub(s.lastSweep) <= sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for id, sess := range s.sessions {
+		if !now.Before(sess.expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+func (s *MemoryStore) Delete(_ context.Context, cookie string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, cookie)
+	return nil
+}
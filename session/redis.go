@@ -0,0 +1,63 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStore saves each session as a Redis string that expires with it, so
+// every replica sees the same sessions and they survive restarts.
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisStore stores sessions under prefix followed by the session ID,
+// "session:" by default. client is usually one of the redis provider.
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "session:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Load(ctx context.Context, cookie string) (map[string]any, error) {
+	if cookie == "" {
+		return nil, nil
+	}
+	b, err := s.client.Get(ctx, s.prefix+cookie).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decode(b)
+}
+
+func (s *RedisStore) Save(ctx context.Context, cookie string, values map[string]any, maxAge time.Duration) (string, error) {
+	b, err := encode(values)
+	if err != nil {
+		return "", fmt.Errorf("session: encode: %w", err)
+	}
+	if cookie != "" {
+		// Only existing sessions keep their ID, so clients cannot choose it.
+		ok, err := s.client.SetXX(ctx, s.prefix+cookie, b, maxAge).Result()
+		if err != nil || ok {
+			return cookie, err
+		}
+	}
+	cookie = newID()
+	return cookie, s.client.Set(ctx, s.prefix+cookie, b, maxAge).Err()
+}
+
+func (s *RedisStore) Delete(ctx context.Context, cookie string) error {
+	if cookie == "" {
+		return nil
+	}
+	return s.client.Del(ctx, s.prefix+cookie).Err()
+}
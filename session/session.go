@@ -0,0 +1,52 @@
+// Package session stores the values of web sessions, keyed by the value of
+// their cookie.
+package session
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"time"
+)
+
+// Store loads and saves session values by cookie value. Values are encoded
+// with encoding/gob, so types other than the basic ones must be registered
+// with gob.Register.
+type Store interface {
+	// Load returns the values of the session whose cookie is cookie, or
+	// nil if it does not exist, has expired or is invalid.
+	Load(ctx context.Context, cookie string) (map[string]any, error)
+	// Save stores values for maxAge and returns the cookie to send. An
+	// empty cookie saves a new session.
+	Save(ctx context.Context, cookie string, values map[string]any, maxAge time.Duration) (string, error)
+	// Delete removes the session whose cookie is cookie.
+	Delete(ctx context.Context, cookie string) error
+}
+
+func encode(values map[string]any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(b []byte) (map[string]any, error) {
+	var values map[string]any
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&values); err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = make(map[string]any)
+	}
+	return values, nil
+}
+
+// newID returns a random session ID of server-side stores.
+func newID() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:])
+	return base64.RawURLEncoding.EncodeToString(b[:])
+}
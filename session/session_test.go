@@ -0,0 +1,80 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var hashKey = []byte(strings.Repeat("h", 32))
+
+func TestCookieStore(t *testing.T) {
+	ctx := context.Background()
+	for name, blockKey := range map[string][]byte{"signed": nil, "encrypted": []byte(strings.Repeat("b", 32))} {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewCookieStore(hashKey, blockKey)
+			require.NoError(t, err)
+			now := time.Now()
+			s.now = func() time.Time { return now }
+
+			cookie, err := s.Save(ctx, "", map[string]any{"user": "alice", "visits": 3}, time.Hour)
+			require.NoError(t, err)
+			if blockKey != nil {
+				assert.NotContains(t, cookie, ".")
+			}
+			values, err := s.Load(ctx, cookie)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{"user": "alice", "visits": 3}, values)
+
+			tampered := []byte(cookie)
+			tampered[len(tampered)/3] ^= 1
+			values, err = s.Load(ctx, string(tampered))
+			assert.NoError(t, err)
+			assert.Nil(t, values)
+
+			other, err := NewCookieStore([]byte(strings.Repeat("x", 32)), blockKey)
+			require.NoError(t, err)
+			values, _ = other.Load(ctx, cookie)
+			assert.Nil(t, values)
+
+			now = now.Add(time.Hour)
+			values, _ = s.Load(ctx, cookie)
+			assert.Nil(t, values)
+		})
+	}
+
+	_, err := NewCookieStore([]byte("short"), nil)
+	assert.Error(t, err)
+	_, err = NewCookieStore(hashKey, []byte("bad size"))
+	assert.Error(t, err)
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	id, err := s.Save(ctx, "chosen-by-client", map[string]any{"n": 1}, time.Minute)
+	require.NoError(t, err)
+	assert.NotEqual(t, "chosen-by-client", id)
+	same, err := s.Save(ctx, id, map[string]any{"n": 2}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, id, same)
+	values, err := s.Load(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 2, values["n"])
+
+	now = now.Add(time.Minute)
+	values, _ = s.Load(ctx, id)
+	assert.Nil(t, values)
+
+	id, _ = s.Save(ctx, "", map[string]any{}, time.Minute)
+	require.NoError(t, s.Delete(ctx, id))
+	values, _ = s.Load(ctx, id)
+	assert.Nil(t, values)
+}
@@ -0,0 +1,241 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyper-micro/hyper/internal/json"
+)
+
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// WriterExporter writes every span as a line of JSON.
+type WriterExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{w: w}
+}
+
+func NewStdoutExporter() *WriterExporter {
+	return NewWriterExporter(os.Stdout)
+}
+
+// NewFileExporter appends spans to the file at path, creating it if needed.
+func NewFileExporter(path string) (*WriterExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterExporter(f), nil
+}
+
+func (e *WriterExporter) Export(_ context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, span := range spans {
+		b, err := json.Marshal(span)
+		if err != nil {
+			return err
+		}
+		if _, err := e.w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *WriterExporter) Shutdown(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if f, ok := e.w.(*os.File); ok && f != os.Stdout && f != os.Stderr {
+		return f.Close()
+	}
+	return nil
+}
+
+type OTLPOption struct {
+	// Endpoint is the collector's traces URL, such as
+	// http://localhost:4318/v1/traces.
+	Endpoint string
+	Headers  map[string]string
+	Timeout  time.Duration
+	Client   *http.Client
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector using OTLP/HTTP
+// with JSON encoding.
+type OTLPExporter struct {
+	opt OTLPOption
+}
+
+func NewOTLPExporter(opt OTLPOption) *OTLPExporter {
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	if opt.Client == nil {
+		opt.Client = &http.Client{Timeout: opt.Timeout}
+	}
+	return &OTLPExporter{opt: opt}
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.opt.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.opt.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.opt.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("tracing: otlp export: %s", resp.Status)
+	}
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(context.Context) error {
+	e.opt.Client.CloseIdleConnections()
+	return nil
+}
+
+type (
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpScopeSpans struct {
+		Scope struct {
+			Name string `json:"name"`
+		} `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpResourceSpans struct {
+		Resource struct {
+			Attributes []otlpKeyValue `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+)
+
+// Status codes of the OTLP Status message.
+const (
+	otlpStatusUnset = 0
+	otlpStatusError = 2
+)
+
+func otlpRequest(spans []SpanData) otlpTraces {
+	byService := make(map[string][]otlpSpan)
+	var services []string
+	for _, s := range spans {
+		if _, ok := byService[s.Service]; !ok {
+			services = append(services, s.Service)
+		}
+		span := otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentID,
+			Name:              s.Name,
+			Kind:              int(s.kind),
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+			Status:            otlpStatus{Code: otlpStatusUnset},
+		}
+		if s.Error != "" {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: s.Error}
+		}
+		byService[s.Service] = append(byService[s.Service], span)
+	}
+
+	var req otlpTraces
+	for _, service := range services {
+		var rs otlpResourceSpans
+		if service != "" {
+			rs.Resource.Attributes = otlpAttributes(map[string]any{"service.name": service})
+		}
+		ss := otlpScopeSpans{Spans: byService[service]}
+		ss.Scope.Name = "github.com/hyper-micro/hyper/tracing"
+		rs.ScopeSpans = []otlpScopeSpans{ss}
+		req.ResourceSpans = append(req.ResourceSpans, rs)
+	}
+	return req
+}
+
+func otlpAttributes(attrs map[string]any) []otlpKeyValue {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		var v otlpValue
+		switch val := attrs[k].(type) {
+		case bool:
+			v.BoolValue = &val
+		case int:
+			s := strconv.Itoa(val)
+			v.IntValue = &s
+		case int64:
+			s := strconv.FormatInt(val, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &val
+		default:
+			s := fmt.Sprint(val)
+			v.StringValue = &s
+		}
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: v})
+	}
+	return kvs
+}
@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+var errInvalidTraceparent = errors.New("tracing: invalid traceparent")
+
+// Carrier reads and writes propagation headers, such as HTTP headers or
+// gRPC metadata.
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+type HeaderCarrier http.Header
+
+func (c HeaderCarrier) Get(key string) string {
+	return http.Header(c).Get(key)
+}
+
+func (c HeaderCarrier) Set(key, value string) {
+	http.Header(c).Set(key, value)
+}
+
+// ParseTraceparent parses a W3C traceparent header value.
+func ParseTraceparent(v string) (SpanContext, error) {
+	var sc SpanContext
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return sc, errInvalidTraceparent
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, errInvalidTraceparent
+	}
+	if len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 || !isLowerHex(parts[1]+parts[2]+parts[3]) {
+		return sc, errInvalidTraceparent
+	}
+	_, _ = hex.Decode(sc.TraceID[:], []byte(parts[1]))
+	_, _ = hex.Decode(sc.SpanID[:], []byte(parts[2]))
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil || !sc.IsValid() {
+		return SpanContext{}, errInvalidTraceparent
+	}
+	sc.Sampled = flags&1 == 1
+	return sc, nil
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Traceparent formats sc as a W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := 0
+	if sc.Sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+}
+
+// Inject writes the trace context of the span in ctx to c.
+func Inject(ctx context.Context, c Carrier) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	c.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		c.Set(TracestateHeader, sc.TraceState)
+	}
+}
+
+// Extract returns a copy of ctx continuing the trace found in c, or ctx
+// itself when c carries no valid trace context.
+func Extract(ctx context.Context, c Carrier) context.Context {
+	sc, err := ParseTraceparent(c.Get(TraceparentHeader))
+	if err != nil {
+		return ctx
+	}
+	sc.TraceState = c.Get(TracestateHeader)
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Transport traces outgoing HTTP requests and propagates their context.
+type Transport struct {
+	Base   http.RoundTripper
+	Tracer *Tracer
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tracer := t.Tracer
+	if tracer == nil {
+		tracer = Default()
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	ctx, span := tracer.Start(req.Context(), "HTTP "+req.Method, WithKind(SpanKindClient))
+	defer span.End()
+	span.SetAttribute("http.request.method", req.Method)
+	span.SetAttribute("url.full", req.URL.Redacted())
+	span.SetAttribute("server.address", req.URL.Host)
+
+	req = req.Clone(ctx)
+	Inject(ctx, HeaderCarrier(req.Header))
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttribute("http.response.status_code", resp.StatusCode)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetError(resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,201 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type TraceID [16]byte
+
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+type SpanID [8]byte
+
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string
+	Remote     bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+type SpanKind int
+
+// Span kinds use the OTLP numbering.
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// SpanData is the immutable record of an ended span handed to exporters.
+type SpanData struct {
+	Name       string         `json:"name"`
+	Kind       string         `json:"kind"`
+	TraceID    string         `json:"traceId"`
+	SpanID     string         `json:"spanId"`
+	ParentID   string         `json:"parentSpanId,omitempty"`
+	Service    string         `json:"service,omitempty"`
+	Start      time.Time      `json:"start"`
+	End        time.Time      `json:"end"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	Error      string         `json:"error,omitempty"`
+
+	kind SpanKind
+}
+
+// Span is an operation being traced. Spans that are not sampled still
+// carry their context for propagation but record nothing.
+type Span struct {
+	tracer   *Tracer
+	sc       SpanContext
+	parentID SpanID
+	name     string
+	kind     SpanKind
+	start    time.Time
+
+	mu    sync.Mutex
+	attrs map[string]any
+	err   string
+	ended bool
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// IsRecording reports whether the span will be exported when it ends.
+func (s *Span) IsRecording() bool {
+	return s != nil && s.sc.Sampled && s.tracer != nil && s.tracer.opt.Exporter != nil
+}
+
+func (s *Span) SetName(name string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+func (s *Span) SetAttribute(key string, value any) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.attrs == nil {
+		s.attrs = make(map[string]any)
+	}
+	s.attrs[key] = value
+}
+
+// RecordError marks the span as failed. A nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.SetError(err.Error())
+}
+
+func (s *Span) SetError(msg string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = msg
+}
+
+// End finishes the span and queues it for export. Only the first call
+// has an effect.
+func (s *Span) End() {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Name:       s.name,
+		Kind:       s.kind.String(),
+		TraceID:    s.sc.TraceID.String(),
+		SpanID:     s.sc.SpanID.String(),
+		Service:    s.tracer.opt.ServiceName,
+		Start:      s.start,
+		End:        time.Now(),
+		Attributes: s.attrs,
+		Error:      s.err,
+		kind:       s.kind,
+	}
+	if s.parentID.IsValid() {
+		data.ParentID = s.parentID.String()
+	}
+	s.mu.Unlock()
+
+	s.tracer.enqueue(data)
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span as the parent of
+// spans started from it.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the current span, or nil. The span of a remote
+// caller is returned as a span that records nothing.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx whose next span
+// continues the trace of a remote caller.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return ContextWithSpan(ctx, &Span{sc: sc})
+}
+
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	return SpanFromContext(ctx).SpanContext()
+}
@@ -0,0 +1,282 @@
+// Package tracing records spans, propagates them with W3C trace context
+// headers and batches finished spans to an exporter.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyper-micro/hyper/logger"
+)
+
+// Sampler decides whether a new root trace is recorded. Child spans follow
+// the decision of their parent.
+type Sampler func(traceID TraceID) bool
+
+func AlwaysSample(TraceID) bool { return true }
+
+func NeverSample(TraceID) bool { return false }
+
+// RatioSampler samples the given fraction of traces, deciding from the
+// trace ID so that every service agrees on the same trace.
+func RatioSampler(ratio float64) Sampler {
+	switch {
+	case ratio >= 1:
+		return AlwaysSample
+	case ratio <= 0:
+		return NeverSample
+	}
+	bound := uint64(ratio * (1 << 63))
+	return func(id TraceID) bool {
+		return binary.BigEndian.Uint64(id[8:])>>1 < bound
+	}
+}
+
+type Option struct {
+	ServiceName string
+	// Exporter receives finished spans. Without one spans still propagate
+	// their context but are not recorded.
+	Exporter Exporter
+	// Sampler defaults to AlwaysSample.
+	Sampler       Sampler
+	BatchSize     int
+	FlushInterval time.Duration
+	QueueSize     int
+	Logger        logger.Logger
+}
+
+type Tracer struct {
+	opt     Option
+	queue   chan SpanData
+	flushCh chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+func New(opt Option) *Tracer {
+	if opt.Sampler == nil {
+		opt.Sampler = AlwaysSample
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 512
+	}
+	if opt.FlushInterval <= 0 {
+		opt.FlushInterval = 5 * time.Second
+	}
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = 2048
+	}
+	if opt.Logger == nil {
+		opt.Logger = logger.Default()
+	}
+
+	t := &Tracer{
+		opt:     opt,
+		queue:   make(chan SpanData, opt.QueueSize),
+		flushCh: make(chan chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	if opt.Exporter != nil {
+		go t.loop()
+	} else {
+		close(t.stopped)
+	}
+	return t
+}
+
+type StartOption func(s *Span)
+
+func WithKind(kind SpanKind) StartOption {
+	return func(s *Span) {
+		s.kind = kind
+	}
+}
+
+func WithAttributes(kv map[string]any) StartOption {
+	return func(s *Span) {
+		for k, v := range kv {
+			s.SetAttribute(k, v)
+		}
+	}
+}
+
+// Start begins a span as a child of the span in ctx, or as the root of a
+// new trace, and returns a context carrying it.
+func (t *Tracer) Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	span := &Span{
+		tracer: t,
+		name:   name,
+		kind:   SpanKindInternal,
+		start:  time.Now(),
+	}
+
+	parent := SpanContextFromContext(ctx)
+	if parent.IsValid() {
+		span.sc = SpanContext{
+			TraceID:    parent.TraceID,
+			Sampled:    parent.Sampled,
+			TraceState: parent.TraceState,
+		}
+		span.parentID = parent.SpanID
+	} else {
+		span.sc.TraceID = newTraceID()
+		span.sc.Sampled = t.opt.Sampler(span.sc.TraceID)
+	}
+	span.sc.SpanID = newSpanID()
+
+	for _, opt := range opts {
+		opt(span)
+	}
+	return ContextWithSpan(ctx, span), span
+}
+
+func (t *Tracer) enqueue(data SpanData) {
+	select {
+	case <-t.done:
+		return
+	default:
+	}
+	select {
+	case t.queue <- data:
+	default:
+		t.dropped.Add(1)
+	}
+}
+
+func (t *Tracer) loop() {
+	defer close(t.stopped)
+
+	ticker := time.NewTicker(t.opt.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, t.opt.BatchSize)
+	export := func() {
+		if n := t.dropped.Swap(0); n > 0 {
+			t.opt.Logger.Warnf("tracing: dropped %d spans, queue full", n)
+		}
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := t.opt.Exporter.Export(ctx, batch); err != nil {
+			t.opt.Logger.Errorf("tracing: export %d spans: %v", len(batch), err)
+		}
+		batch = make([]SpanData, 0, t.opt.BatchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case data := <-t.queue:
+				batch = append(batch, data)
+				if len(batch) >= t.opt.BatchSize {
+					export()
+				}
+			default:
+				export()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) >= t.opt.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case ack := <-t.flushCh:
+			drain()
+			close(ack)
+		case <-t.done:
+			drain()
+			return
+		}
+	}
+}
+
+// Flush exports every span ended so far.
+func (t *Tracer) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case t.flushCh <- ack:
+	case <-t.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports pending spans and shuts the exporter down. Spans ended
+// afterwards are discarded.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	var err error
+	t.once.Do(func() {
+		close(t.done)
+		select {
+		case <-t.stopped:
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		if t.opt.Exporter != nil {
+			err = t.opt.Exporter.Shutdown(ctx)
+		}
+	})
+	return err
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+var tracer atomic.Pointer[Tracer]
+
+func init() {
+	SetDefault(New(Option{}))
+}
+
+// SetDefault replaces the tracer used by the package-level functions.
+// A nil tracer is ignored.
+func SetDefault(t *Tracer) {
+	if t == nil {
+		return
+	}
+	tracer.Store(t)
+}
+
+func Default() *Tracer {
+	return tracer.Load()
+}
+
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	return Default().Start(ctx, name, opts...)
+}
@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	stdErrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyper-micro/hyper/internal/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceparent(t *testing.T) {
+	const header = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(header)
+	require.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, header, sc.Traceparent())
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		_, err := ParseTraceparent(invalid)
+		assert.Error(t, err, invalid)
+	}
+	_, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	assert.NoError(t, err)
+}
+
+func TestTracer_PropagationAndExport(t *testing.T) {
+	var out bytes.Buffer
+	tracer := New(Option{ServiceName: "svc", Exporter: NewWriterExporter(&out)})
+
+	in := http.Header{}
+	in.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	in.Set(TracestateHeader, "vendor=1")
+	ctx := Extract(context.Background(), HeaderCarrier(in))
+
+	ctx, server := tracer.Start(ctx, "GET /users", WithKind(SpanKindServer))
+	_, client := tracer.Start(ctx, "SELECT", WithKind(SpanKindClient), WithAttributes(map[string]any{"db.system": "mysql"}))
+	client.RecordError(stdErrors.New("timeout"))
+	client.End()
+	client.End()
+
+	outHeader := http.Header{}
+	Inject(ctx, HeaderCarrier(outHeader))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+server.SpanContext().SpanID.String()+"-01", outHeader.Get(TraceparentHeader))
+	assert.Equal(t, "vendor=1", outHeader.Get(TracestateHeader))
+	server.End()
+
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var spans [2]SpanData
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal([]byte(line), &spans[i]))
+	}
+	assert.Equal(t, "SELECT", spans[0].Name)
+	assert.Equal(t, server.SpanContext().SpanID.String(), spans[0].ParentID)
+	assert.Equal(t, "timeout", spans[0].Error)
+	assert.Equal(t, "mysql", spans[0].Attributes["db.system"])
+	assert.Equal(t, "00f067aa0ba902b7", spans[1].ParentID)
+	assert.Equal(t, "svc", spans[1].Service)
+}
+
+func TestTracer_Sampling(t *testing.T) {
+	var out bytes.Buffer
+	tracer := New(Option{Exporter: NewWriterExporter(&out), Sampler: NeverSample})
+	ctx, root := tracer.Start(context.Background(), "root")
+	_, child := tracer.Start(ctx, "child")
+	assert.False(t, root.IsRecording())
+	assert.False(t, child.IsRecording())
+	assert.Equal(t, root.SpanContext().TraceID, child.SpanContext().TraceID)
+	child.End()
+	root.End()
+	require.NoError(t, tracer.Shutdown(context.Background()))
+	assert.Empty(t, out.String())
+
+	assert.True(t, RatioSampler(1)(newTraceID()))
+	assert.False(t, RatioSampler(0)(newTraceID()))
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var got map[string]any
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		b, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(b, &got))
+	}))
+	defer collector.Close()
+
+	tracer := New(Option{
+		ServiceName: "svc",
+		Exporter: NewOTLPExporter(OTLPOption{
+			Endpoint: collector.URL + "/v1/traces",
+			Headers:  map[string]string{"Authorization": "secret"},
+		}),
+	})
+	_, span := tracer.Start(context.Background(), "op", WithKind(SpanKindServer))
+	span.SetAttribute("http.response.status_code", 500)
+	span.SetError("boom")
+	span.End()
+	require.NoError(t, tracer.Flush(context.Background()))
+	require.NoError(t, tracer.Shutdown(context.Background()))
+
+	rs := got["resourceSpans"].([]any)[0].(map[string]any)
+	assert.Equal(t, "svc", rs["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)["value"].(map[string]any)["stringValue"])
+	otlpSpan := rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)[0].(map[string]any)
+	assert.Equal(t, "op", otlpSpan["name"])
+	assert.Equal(t, float64(SpanKindServer), otlpSpan["kind"])
+	assert.Equal(t, span.SpanContext().TraceID.String(), otlpSpan["traceId"])
+	assert.Equal(t, map[string]any{"code": float64(2), "message": "boom"}, otlpSpan["status"])
+	assert.Equal(t, "500", otlpSpan["attributes"].([]any)[0].(map[string]any)["value"].(map[string]any)["intValue"])
+}